@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const adoptExample = `  git get adopt ~/src/git-get
+  git get adopt --dry-run ~/work/*`
+
+func newAdoptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get adopt <PATH>...",
+		Short:        "Move existing repositories into the directory tree based on their remote URL.",
+		Long:         "Move existing repositories into the directory tree based on their remote URL, where \"git get\" would clone them.\nWith --prune-empty, source directories left empty are removed too, as long as they are inside the home directory.",
+		Example:      adoptExample,
+		RunE:         runAdoptCommand,
+		Args:         cobra.MinimumNArgs(1),
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().BoolP(cfg.KeyDryRun, "n", false, "Only print where the repositories would be moved.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultHost, "t", cfg.Defaults[cfg.KeyDefaultHost], "Host to use when the remote URL doesn't have a specified host.")
+	cmd.PersistentFlags().Bool(cfg.KeyPruneEmpty, false, "Remove source directories left empty, as long as they are inside the home directory.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultScheme, "c", cfg.Defaults[cfg.KeyDefaultScheme], "Scheme to use when the remote URL doesn't have a specified scheme.")
+	cmd.PersistentFlags().BoolP(cfg.KeySkipHost, "s", false, "Don't create a directory for host.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are moved.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runAdoptCommand(cmd *cobra.Command, args []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+
+	config := &pkg.AdoptCfg{
+		DefHost:    viper.GetString(cfg.KeyDefaultHost),
+		DefScheme:  viper.GetString(cfg.KeyDefaultScheme),
+		DryRun:     viper.GetBool(cfg.KeyDryRun),
+		Paths:      args,
+		PruneEmpty: viper.GetBool(cfg.KeyPruneEmpty),
+		Root:       viper.GetString(cfg.KeyReposRoot),
+		Routes:     cfg.Routes(),
+		SkipHost:   viper.GetBool(cfg.KeySkipHost),
+	}
+
+	// An explicit --root flag moves the repos there, regardless of the routes.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
+	}
+
+	return pkg.Adopt(config)
+}
+
+func runAdopt(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the adopt command
+	cmd := newAdoptCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const browseExample = `  git get browse
+  git get browse --branch --file pkg/get.go github.com/grdl/git-get
+  git get browse --commit HEAD~1 --print
+  git get browse grdl/git-get`
+
+func newBrowseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get browse [REPO]",
+		Short:        "Open the web page of a repository on its forge or print its URL.",
+		Long:         "Open the web page of a repository on the forge hosting its remote, or print its URL.\nREPO is a path of a repository (absolute, or relative to the current directory or the repos root) or a repository URL.\nWithout REPO, the repository in the current directory is used.",
+		Example:      browseExample,
+		RunE:         runBrowseCommand,
+		Args:         cobra.MaximumNArgs(1),
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().BoolP(cfg.KeyBranch, "b", false, "Point at the current branch.")
+	cmd.PersistentFlags().String(cfg.KeyBrowser, "", "Command opening the URL. Defaults to the BROWSER env var or the system's default browser.")
+	cmd.PersistentFlags().String(cfg.KeyCommit, "", "Point at a commit, eg HEAD~1 or a commit hash.")
+	cmd.PersistentFlags().String(cfg.KeyFile, "", "Point at a file, given by its path relative to the repository root.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultHost, "t", cfg.Defaults[cfg.KeyDefaultHost], "Host to use when REPO doesn't have a specified host.")
+	cmd.PersistentFlags().BoolP(cfg.KeyPrint, "p", false, "Print the URL instead of opening it.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultScheme, "c", cfg.Defaults[cfg.KeyDefaultScheme], "Scheme to use when REPO doesn't have a specified scheme.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runBrowseCommand(_ *cobra.Command, args []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+
+	var target string
+	if len(args) > 0 {
+		target = args[0]
+	}
+
+	config := &pkg.BrowseCfg{
+		Branch:    viper.GetBool(cfg.KeyBranch),
+		Browser:   viper.GetString(cfg.KeyBrowser),
+		Commit:    viper.GetString(cfg.KeyCommit),
+		DefHost:   viper.GetString(cfg.KeyDefaultHost),
+		DefScheme: viper.GetString(cfg.KeyDefaultScheme),
+		File:      viper.GetString(cfg.KeyFile),
+		Print:     viper.GetBool(cfg.KeyPrint),
+		Root:      viper.GetString(cfg.KeyReposRoot),
+		Target:    target,
+	}
+
+	return pkg.Browse(config)
+}
+
+func runBrowse(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the browse command
+	cmd := newBrowseCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const configExample = `  git get config
+  git get config root skip-host
+  git get --profile work config`
+
+// secretKeys are keys whose values are masked in the output.
+var secretKeys = []string{cfg.KeyToken}
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get config [KEY]...",
+		Short:        "Show effective configuration values and where they come from.",
+		Long:         "Show the effective value of each configuration key and its source: flag default, gitconfig, user config file, project config file or env variable.\nPer-host sections of config files are shown too. Tokens are masked.",
+		Example:      configExample,
+		RunE:         runConfigCommand,
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	return cmd
+}
+
+func runConfigCommand(_ *cobra.Command, args []string) error {
+	defaults := flagDefaults()
+
+	keys := args
+	if len(keys) == 0 {
+		defaults[cfg.KeyProfile] = ""
+		keys = slices.Sorted(maps.Keys(defaults))
+	}
+
+	fmt.Printf("user config:    %s\n", cfg.UserConfigFile())
+	fmt.Printf("project config: %s\n\n", cfg.ProjectConfigFile())
+
+	for _, key := range keys {
+		value, source, ok := cfg.Lookup(key)
+		if !ok {
+			value = defaults[key]
+		}
+
+		fmt.Printf("%s = %s (%s)\n", key, mask(key, value), source)
+	}
+
+	for _, host := range cfg.Hosts() {
+		fmt.Printf("\n[hosts.%q]\n", host)
+
+		for _, key := range cfg.HostKeys(host) {
+			value, source, _ := cfg.HostLookup(host, key)
+			fmt.Printf("%s = %s (%s)\n", key, mask(key, value), source)
+		}
+	}
+
+	return nil
+}
+
+// flagDefaults returns the default values of flags of all commands. Keys used by multiple commands get the first default found.
+func flagDefaults() map[string]string {
+	defaults := make(map[string]string)
+
+	commands := []*cobra.Command{
+		newGetCommand(), newListCommand(), newDaemonCommand(), newPruneCommand(), newRmCommand(), newAdoptCommand(),
+		newSyncCommand(), newTagCommand(), newDiscoverCommand(), newBrowseCommand(),
+	}
+
+	for _, cmd := range commands {
+		cmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Name == "help" || flag.Name == "version" {
+				return
+			}
+
+			if _, ok := defaults[flag.Name]; !ok {
+				defaults[flag.Name] = strings.Trim(flag.DefValue, "[]")
+			}
+		})
+	}
+
+	return defaults
+}
+
+// mask hides secret values. Only the last 4 characters of long values are shown, so different tokens can be told apart.
+func mask(key string, value string) string {
+	if !slices.Contains(secretKeys, key) || value == "" {
+		return value
+	}
+
+	if len(value) <= 12 {
+		return "****"
+	}
+
+	return "****" + value[len(value)-4:]
+}
+
+func runConfig(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the config command
+	cmd := newConfigCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
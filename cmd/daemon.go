@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newDaemonCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get daemon",
+		Short:        "Keep status of all repositories warm and serve it over a Unix socket to 'git list --daemon'.",
+		RunE:         runDaemonCommand,
+		Args:         cobra.NoArgs,
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().DurationP(cfg.KeyInterval, "i", time.Minute, "How often to reload status of all repositories. Repos changed on disk are reloaded immediately.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().String(cfg.KeySocket, pkg.DefaultSocket(), "Path to the Unix socket to listen on.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runDaemonCommand(_ *cobra.Command, _ []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+
+	config := &pkg.DaemonCfg{
+		Interval: viper.GetDuration(cfg.KeyInterval),
+		Root:     viper.GetString(cfg.KeyReposRoot),
+		Socket:   viper.GetString(cfg.KeySocket),
+	}
+
+	return pkg.Daemon(config)
+}
+
+func runDaemon(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the daemon command
+	cmd := newDaemonCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/forge"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const discoverExample = `  git get discover grdl > repos.txt
+  git get discover --forge gitlab --include 'backend/*' --clone my-group
+  git get discover --forge gitea --api-url https://git.example.com/api/v1 --forks --archived my-org`
+
+func newDiscoverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get discover <OWNER>",
+		Short:        "List all repositories of an organization, group or user from a forge API and print them as a dump file or clone them.",
+		Long:         "List all repositories of an organization, group or user from a forge API (GitHub, GitLab or Gitea).\nThe list is printed as a dump file which can be used with \"git get --dump\" or \"git get sync\".\nWith --clone, the repositories which don't exist under the repos root are cloned.\nThe access token is read from the GITGET_TOKEN env var or, if it's not set, from GITHUB_TOKEN, GITLAB_TOKEN or GITEA_TOKEN.",
+		Example:      discoverExample,
+		RunE:         runDiscoverCommand,
+		Args:         cobra.ExactArgs(1),
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().String(cfg.KeyAPIURL, "", "Base URL of the forge API, for self-hosted instances. The public instance of the forge is used if empty.")
+	cmd.PersistentFlags().Bool(cfg.KeyArchived, false, "Include archived repositories.")
+	cmd.PersistentFlags().Bool(cfg.KeyClone, false, "Clone the repositories instead of printing them.")
+	cmd.PersistentFlags().StringSlice(cfg.KeyExclude, nil, "Skip repositories with names matching a glob pattern. Can be repeated.")
+	cmd.PersistentFlags().String(cfg.KeyForge, forge.GitHub, fmt.Sprintf("Forge to query. Allowed values: [%s].", strings.Join(forge.Allowed, ", ")))
+	cmd.PersistentFlags().Bool(cfg.KeyForks, false, "Include forks.")
+	cmd.PersistentFlags().StringSlice(cfg.KeyInclude, nil, "Only list repositories with names matching a glob pattern. Can be repeated.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultHost, "t", cfg.Defaults[cfg.KeyDefaultHost], "Host to use when a cloned URL doesn't have a specified host.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultScheme, "c", cfg.Defaults[cfg.KeyDefaultScheme], "Scheme of the listed URLs: ssh URLs are used with ssh, https URLs otherwise.")
+	cmd.PersistentFlags().BoolP(cfg.KeySkipHost, "s", false, "Don't create a directory for host.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runDiscoverCommand(cmd *cobra.Command, args []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+
+	config := &pkg.DiscoverCfg{
+		APIURL:    viper.GetString(cfg.KeyAPIURL),
+		Archived:  viper.GetBool(cfg.KeyArchived),
+		Clone:     viper.GetBool(cfg.KeyClone),
+		DefHost:   viper.GetString(cfg.KeyDefaultHost),
+		DefScheme: viper.GetString(cfg.KeyDefaultScheme),
+		Exclude:   viper.GetStringSlice(cfg.KeyExclude),
+		Forge:     viper.GetString(cfg.KeyForge),
+		Forks:     viper.GetBool(cfg.KeyForks),
+		Include:   viper.GetStringSlice(cfg.KeyInclude),
+		Owner:     args[0],
+		Root:      viper.GetString(cfg.KeyReposRoot),
+		Routes:    cfg.Routes(),
+		SkipHost:  viper.GetBool(cfg.KeySkipHost),
+		Token:     viper.GetString(cfg.KeyToken),
+	}
+
+	// An explicit --root flag puts the repos there, regardless of the routes.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
+	}
+
+	return pkg.Discover(config)
+}
+
+func runDiscover(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the discover command
+	cmd := newDiscoverCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -15,7 +15,10 @@ import (
 const getExample = `  git get grdl/git-get
   git get https://github.com/grdl/git-get.git
   git get git@github.com:grdl/git-get.git
-  git get -d path/to/dump/file`
+  git get -d path/to/dump/file
+  git get -d git+https://github.com/me/dotfiles#repos.txt
+  git get -d path/to/dump/file --tag backend
+  git get me/git-get --upstream grdl/git-get --track-upstream`
 
 func newGetCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -31,9 +34,12 @@ func newGetCommand() *cobra.Command {
 	cmd.PersistentFlags().StringP(cfg.KeyBranch, "b", "", "Branch (or tag) to checkout after cloning.")
 	cmd.PersistentFlags().StringP(cfg.KeyDefaultHost, "t", cfg.Defaults[cfg.KeyDefaultHost], "Host to use when <REPO> doesn't have a specified host.")
 	cmd.PersistentFlags().StringP(cfg.KeyDefaultScheme, "c", cfg.Defaults[cfg.KeyDefaultScheme], "Scheme to use when <REPO> doesn't have a specified scheme.")
-	cmd.PersistentFlags().StringP(cfg.KeyDump, "d", "", "Path to a dump file listing repos to clone. Ignored when <REPO> argument is used.")
+	cmd.PersistentFlags().StringP(cfg.KeyDump, "d", "", "Path to a dump file listing repos to clone. Can be an http(s) URL or a file in a git repo: git+<URL>#<path>. Ignored when <REPO> argument is used.")
 	cmd.PersistentFlags().BoolP(cfg.KeySkipHost, "s", false, "Don't create a directory for host.")
 	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().StringSlice(cfg.KeyTag, nil, "Clone only repositories from the dump file with given tag. Can be repeated to clone repositories with any of the tags.")
+	cmd.PersistentFlags().String(cfg.KeyUpstream, "", "URL of the repo a fork was made from, added as the \"upstream\" remote after cloning.")
+	cmd.PersistentFlags().Bool(cfg.KeyTrackUpstream, false, "Make the checked out branch track upstream instead of origin. Requires --upstream.")
 	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
 	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
 
@@ -44,7 +50,7 @@ func newGetCommand() *cobra.Command {
 	return cmd
 }
 
-func runGetCommand(_ *cobra.Command, args []string) error {
+func runGetCommand(cmd *cobra.Command, args []string) error {
 	var url string
 	if len(args) > 0 {
 		url = args[0]
@@ -53,13 +59,22 @@ func runGetCommand(_ *cobra.Command, args []string) error {
 	cfg.Expand(cfg.KeyReposRoot)
 
 	config := &pkg.GetCfg{
-		Branch:    viper.GetString(cfg.KeyBranch),
-		DefHost:   viper.GetString(cfg.KeyDefaultHost),
-		DefScheme: viper.GetString(cfg.KeyDefaultScheme),
-		Dump:      viper.GetString(cfg.KeyDump),
-		SkipHost:  viper.GetBool(cfg.KeySkipHost),
-		Root:      viper.GetString(cfg.KeyReposRoot),
-		URL:       url,
+		Branch:        viper.GetString(cfg.KeyBranch),
+		DefHost:       viper.GetString(cfg.KeyDefaultHost),
+		DefScheme:     viper.GetString(cfg.KeyDefaultScheme),
+		Dump:          viper.GetString(cfg.KeyDump),
+		SkipHost:      viper.GetBool(cfg.KeySkipHost),
+		Root:          viper.GetString(cfg.KeyReposRoot),
+		Routes:        cfg.Routes(),
+		Tags:          viper.GetStringSlice(cfg.KeyTag),
+		TrackUpstream: viper.GetBool(cfg.KeyTrackUpstream),
+		Upstream:      viper.GetString(cfg.KeyUpstream),
+		URL:           url,
+	}
+
+	// An explicit --root flag puts the repo there, regardless of the routes.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
 	}
 
 	return pkg.Get(config)
@@ -67,7 +82,7 @@ func runGetCommand(_ *cobra.Command, args []string) error {
 
 func runGet(args []string) {
 	// Initialize configuration
-	cfg.Init(&git.ConfigGlobal{})
+	cfg.Init(&git.Config{})
 
 	// Create and execute the get command
 	cmd := newGetCommand()
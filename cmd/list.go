@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/grdl/git-get/pkg"
 	"github.com/grdl/git-get/pkg/cfg"
@@ -23,9 +24,28 @@ func newListCommand() *cobra.Command {
 		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
 	}
 
+	cmd.PersistentFlags().Bool(cfg.KeyDaemon, false, "Read repositories status from a running 'git get daemon'. Falls back to loading it directly if the daemon isn't available or routes to other roots are configured, since the daemon serves a single root.")
+	cmd.PersistentFlags().Bool(cfg.KeyDiskUsage, false, "Show disk usage of each repository (worktree, .git directory and LFS objects) and totals of each directory in tree output.")
 	cmd.PersistentFlags().BoolP(cfg.KeyFetch, "f", false, "First fetch from remotes before listing repositories.")
+	cmd.PersistentFlags().Duration(cfg.KeyFetchInterval, 0, "How often to fetch from remotes in watch mode. Disabled when 0.")
+	cmd.PersistentFlags().Bool(cfg.KeyFix, false, "Move repositories reported by --misplaced into the path matching their remote URL.")
+	cmd.PersistentFlags().String(cfg.KeyGroupBy, cfg.GroupPath, fmt.Sprintf("Group repositories in the tree output by their path or by their tags. Allowed values: [%s].", strings.Join(cfg.AllowedGroupBy, ", ")))
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultHost, "t", cfg.Defaults[cfg.KeyDefaultHost], "Host to use when the remote URL doesn't have a specified host. Used by --misplaced.")
+	cmd.PersistentFlags().DurationP(cfg.KeyInterval, "i", 5*time.Second, "How often to reload repositories status in watch mode.")
+	cmd.PersistentFlags().Bool(cfg.KeyLastCommit, false, "Show date, author and subject of the last commit and the time since the last local activity.")
+	cmd.PersistentFlags().Bool(cfg.KeyMisplaced, false, "Report repositories whose path doesn't match their remote URL (eg, forks, renamed or transferred repos) instead of the status.")
+	cmd.PersistentFlags().String(cfg.KeyOlderThan, "", "Show only repositories without local activity (commits or worktree changes) for longer than given age, eg 180d, 8w, 1y.")
 	cmd.PersistentFlags().StringP(cfg.KeyOutput, "o", cfg.Defaults[cfg.KeyOutput], fmt.Sprintf("Output format. Allowed values: [%s].", strings.Join(cfg.AllowedOut, ", ")))
+	cmd.PersistentFlags().Bool(cfg.KeyPullRequests, false, "Show pull requests and CI checks state of branches of repos hosted on GitHub, GitLab or Gitea.")
+	cmd.PersistentFlags().Bool(cfg.KeyReport, false, "Print a report of branches with gone upstream, merged or stale branches, unpushed commits and stashes instead of the status.")
 	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultScheme, "c", cfg.Defaults[cfg.KeyDefaultScheme], "Scheme to use when the remote URL doesn't have a specified scheme. Used by --misplaced.")
+	cmd.PersistentFlags().BoolP(cfg.KeySkipHost, "s", false, "Repositories are stored without a directory for host. Used by --misplaced.")
+	cmd.PersistentFlags().String(cfg.KeySocket, pkg.DefaultSocket(), "Path to the daemon's Unix socket.")
+	cmd.PersistentFlags().String(cfg.KeySort, cfg.Defaults[cfg.KeySort], fmt.Sprintf("Sort repositories by given key. Prefix the key with \"-\" to sort in descending order. Allowed values: [%s].", strings.Join(cfg.AllowedSort, ", ")))
+	cmd.PersistentFlags().Int(cfg.KeyStaleDays, 90, "Number of days without commits after which a branch is reported as stale.")
+	cmd.PersistentFlags().StringSlice(cfg.KeyTag, nil, "Show only repositories with given tag. Can be repeated to show repositories with any of the tags.")
+	cmd.PersistentFlags().BoolP(cfg.KeyWatch, "w", false, "Keep reloading repositories status and redraw the output in place. Changed repos are highlighted.")
 	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
 	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
 
@@ -36,13 +56,38 @@ func newListCommand() *cobra.Command {
 	return cmd
 }
 
-func runListCommand(_ *cobra.Command, _ []string) error {
+func runListCommand(cmd *cobra.Command, _ []string) error {
 	cfg.Expand(cfg.KeyReposRoot)
 
 	config := &pkg.ListCfg{
-		Fetch:  viper.GetBool(cfg.KeyFetch),
-		Output: viper.GetString(cfg.KeyOutput),
-		Root:   viper.GetString(cfg.KeyReposRoot),
+		Daemon:        viper.GetBool(cfg.KeyDaemon),
+		DefHost:       viper.GetString(cfg.KeyDefaultHost),
+		DefScheme:     viper.GetString(cfg.KeyDefaultScheme),
+		DiskUsage:     viper.GetBool(cfg.KeyDiskUsage),
+		Fetch:         viper.GetBool(cfg.KeyFetch),
+		FetchInterval: viper.GetDuration(cfg.KeyFetchInterval),
+		Fix:           viper.GetBool(cfg.KeyFix),
+		GroupBy:       viper.GetString(cfg.KeyGroupBy),
+		Interval:      viper.GetDuration(cfg.KeyInterval),
+		LastCommit:    viper.GetBool(cfg.KeyLastCommit),
+		Misplaced:     viper.GetBool(cfg.KeyMisplaced),
+		OlderThan:     viper.GetString(cfg.KeyOlderThan),
+		Output:        viper.GetString(cfg.KeyOutput),
+		PullRequests:  viper.GetBool(cfg.KeyPullRequests),
+		Report:        viper.GetBool(cfg.KeyReport),
+		Root:          viper.GetString(cfg.KeyReposRoot),
+		Routes:        cfg.Routes(),
+		SkipHost:      viper.GetBool(cfg.KeySkipHost),
+		Socket:        viper.GetString(cfg.KeySocket),
+		Sort:          viper.GetString(cfg.KeySort),
+		StaleDays:     viper.GetInt(cfg.KeyStaleDays),
+		Tags:          viper.GetStringSlice(cfg.KeyTag),
+		Watch:         viper.GetBool(cfg.KeyWatch),
+	}
+
+	// An explicit --root flag lists only the repos under it.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
 	}
 
 	return pkg.List(config)
@@ -50,7 +95,7 @@ func runListCommand(_ *cobra.Command, _ []string) error {
 
 func runList(args []string) {
 	// Initialize configuration
-	cfg.Init(&git.ConfigGlobal{})
+	cfg.Init(&git.Config{})
 
 	// Create and execute the list command
 	cmd := newListCommand()
@@ -7,14 +7,50 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
 )
 
+// commands lists the commands which can be invoked as "git get <command>" or "git-get <command>".
+var commands = []string{"get", "list", "daemon", "prune-branches", "rm", "adopt", "sync", "tag", "discover", "browse", "config"}
+
 func main() {
+	// The profile option can be given anywhere, also before the command name, so it's removed before the command is determined.
+	args, profile := extractProfile(os.Args[1:])
+	os.Args = append(os.Args[:1], args...)
+	cfg.SetProfile(profile)
+
 	command, args := determineCommand()
 	executeCommand(command, args)
 }
 
+// extractProfile removes the "--profile <name>" (or "--profile=<name>") option from args and returns the profile name.
+// It's handled here rather than by each command because the profile must be known before the config is loaded.
+func extractProfile(args []string) ([]string, string) {
+	var (
+		rest    []string
+		profile string
+	)
+
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--":
+			return append(rest, args[i:]...), profile
+		case arg == "--"+cfg.KeyProfile && i+1 < len(args):
+			profile = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--"+cfg.KeyProfile+"="):
+			profile = strings.TrimPrefix(arg, "--"+cfg.KeyProfile+"=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	return rest, profile
+}
+
 func determineCommand() (string, []string) {
 	programName := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
 
@@ -29,7 +65,7 @@ func determineCommand() (string, []string) {
 }
 
 func handleGitGetInvocation() (string, []string) {
-	if len(os.Args) > 1 && (os.Args[1] == "get" || os.Args[1] == "list") {
+	if len(os.Args) > 1 && slices.Contains(commands, os.Args[1]) {
 		return os.Args[1], os.Args[2:]
 	}
 
@@ -54,6 +90,24 @@ func executeCommand(command string, args []string) {
 		runGet(args)
 	case "list":
 		runList(args)
+	case "daemon":
+		runDaemon(args)
+	case "prune-branches":
+		runPrune(args)
+	case "rm":
+		runRm(args)
+	case "adopt":
+		runAdopt(args)
+	case "sync":
+		runSync(args)
+	case "tag":
+		runTag(args)
+	case "discover":
+		runDiscover(args)
+	case "browse":
+		runBrowse(args)
+	case "config":
+		runConfig(args)
 	default:
 		runGet(os.Args[1:])
 	}
@@ -135,6 +135,54 @@ func TestHandleGitGetInvocation(t *testing.T) {
 			wantCmd:  "list",
 			wantArgs: []string{"--fetch"},
 		},
+		{
+			name:     "with daemon subcommand",
+			args:     []string{"git-get", "daemon", "--root", "/tmp"},
+			wantCmd:  "daemon",
+			wantArgs: []string{"--root", "/tmp"},
+		},
+		{
+			name:     "with rm subcommand",
+			args:     []string{"git-get", "rm", "--force", "github.com/user/repo"},
+			wantCmd:  "rm",
+			wantArgs: []string{"--force", "github.com/user/repo"},
+		},
+		{
+			name:     "with adopt subcommand",
+			args:     []string{"git-get", "adopt", "--dry-run", "/src/repo"},
+			wantCmd:  "adopt",
+			wantArgs: []string{"--dry-run", "/src/repo"},
+		},
+		{
+			name:     "with sync subcommand",
+			args:     []string{"git-get", "sync", "--prune", "repos.txt"},
+			wantCmd:  "sync",
+			wantArgs: []string{"--prune", "repos.txt"},
+		},
+		{
+			name:     "with tag subcommand",
+			args:     []string{"git-get", "tag", "github.com/user/repo", "backend"},
+			wantCmd:  "tag",
+			wantArgs: []string{"github.com/user/repo", "backend"},
+		},
+		{
+			name:     "with discover subcommand",
+			args:     []string{"git-get", "discover", "--forge", "gitlab", "grdl"},
+			wantCmd:  "discover",
+			wantArgs: []string{"--forge", "gitlab", "grdl"},
+		},
+		{
+			name:     "with browse subcommand",
+			args:     []string{"git-get", "browse", "--print", "grdl/git-get"},
+			wantCmd:  "browse",
+			wantArgs: []string{"--print", "grdl/git-get"},
+		},
+		{
+			name:     "with config subcommand",
+			args:     []string{"git-get", "config", "root"},
+			wantCmd:  "config",
+			wantArgs: []string{"root"},
+		},
 		{
 			name:     "with invalid subcommand",
 			args:     []string{"git-get", "invalid", "user/repo"},
@@ -261,3 +309,55 @@ func TestHandleDefaultInvocation(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantArgs    []string
+		wantProfile string
+	}{
+		{
+			name:        "no profile",
+			args:        []string{"--fetch", "user/repo"},
+			wantArgs:    []string{"--fetch", "user/repo"},
+			wantProfile: "",
+		},
+		{
+			name:        "separate value",
+			args:        []string{"--profile", "work", "user/repo"},
+			wantArgs:    []string{"user/repo"},
+			wantProfile: "work",
+		},
+		{
+			name:        "value after equals sign",
+			args:        []string{"--out", "flat", "--profile=work"},
+			wantArgs:    []string{"--out", "flat"},
+			wantProfile: "work",
+		},
+		{
+			name:        "after double dash",
+			args:        []string{"--", "--profile", "work"},
+			wantArgs:    []string{"--", "--profile", "work"},
+			wantProfile: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotArgs, gotProfile := extractProfile(tt.args)
+
+			if gotProfile != tt.wantProfile {
+				t.Errorf("extractProfile() profile = %v, want %v", gotProfile, tt.wantProfile)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("extractProfile() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
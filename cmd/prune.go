@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newPruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get prune-branches",
+		Short:        "Delete local branches merged into the default branch or whose upstream is gone, in all repositories.",
+		Long:         "Delete local branches merged into the default branch or whose upstream is gone, in all repositories.\nThe current branch and branches with commits not pushed to any remote are never deleted.",
+		RunE:         runPruneCommand,
+		Args:         cobra.NoArgs,
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().BoolP(cfg.KeyDryRun, "n", false, "Only print which branches would be deleted.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().StringSlice(cfg.KeyTag, nil, "Prune only repositories with given tag. Can be repeated to prune repositories with any of the tags.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runPruneCommand(cmd *cobra.Command, _ []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+
+	config := &pkg.PruneCfg{
+		DryRun: viper.GetBool(cfg.KeyDryRun),
+		Root:   viper.GetString(cfg.KeyReposRoot),
+		Routes: cfg.Routes(),
+		Tags:   viper.GetStringSlice(cfg.KeyTag),
+	}
+
+	// An explicit --root flag prunes only that root, regardless of the routes.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
+	}
+
+	return pkg.PruneBranches(config)
+}
+
+func runPrune(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the prune-branches command
+	cmd := newPruneCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
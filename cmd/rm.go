@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const rmExample = `  git get rm github.com/grdl/git-get
+  git get rm ~/repositories/github.com/grdl/git-get
+  git get rm --archive ~/archive github.com/grdl/git-get`
+
+func newRmCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get rm <PATH>...",
+		Short:        "Remove repositories and their parent directories left empty.",
+		Long:         "Remove repositories and their parent directories left empty, up to the repos root.\n<PATH> can be relative to the current directory or to the repos root.\nRepositories outside of the repos root and repositories with uncommitted or untracked files, stashes, operations in progress, commits not pushed to any remote or other repositories nested inside are removed only with --force.",
+		Example:      rmExample,
+		RunE:         runRmCommand,
+		Args:         cobra.MinimumNArgs(1),
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().StringP(cfg.KeyArchive, "a", "", "Archive repositories into given directory before removing them.")
+	cmd.PersistentFlags().String(cfg.KeyArchiveFormat, cfg.ArchiveTar, fmt.Sprintf("Archive format: tar keeps the whole directory, bundle keeps only the committed history and stashes. Allowed values: [%s].", strings.Join(cfg.AllowedArchive, ", ")))
+	cmd.PersistentFlags().BoolP(cfg.KeyForce, "f", false, "Remove repositories even if some work would be lost or they are outside of the repos root.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runRmCommand(cmd *cobra.Command, args []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+	cfg.Expand(cfg.KeyArchive)
+
+	config := &pkg.RmCfg{
+		Archive:       viper.GetString(cfg.KeyArchive),
+		ArchiveFormat: viper.GetString(cfg.KeyArchiveFormat),
+		Force:         viper.GetBool(cfg.KeyForce),
+		Paths:         args,
+		Root:          viper.GetString(cfg.KeyReposRoot),
+		Routes:        cfg.Routes(),
+	}
+
+	// An explicit --root flag allows removing repos only from that root, regardless of the routes.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
+	}
+
+	return pkg.Rm(config)
+}
+
+func runRm(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the rm command
+	cmd := newRmCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const syncExample = `  git get sync repos.txt
+  git get sync --dry-run --prune git+https://github.com/me/team-repos#repos.txt
+  git get sync --prune --archive ~/archive --yes repos.txt`
+
+func newSyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get sync <MANIFEST>",
+		Short:        "Reconcile the repos root with a manifest: clone missing repos, update existing ones and report (or prune) the others.",
+		Long:         "Treat a manifest (a dump file) as the desired state of the repos root.\nMissing repos are cloned, existing ones are fetched and fast-forwarded, and repos not in the manifest are reported.\nWith --prune, the ones without any work which would be lost are removed.\nClones and removals are printed first and applied after confirmation, updates are applied without asking.",
+		Example:      syncExample,
+		RunE:         runSyncCommand,
+		Args:         cobra.ExactArgs(1),
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().StringP(cfg.KeyArchive, "a", "", "Directory where pruned repositories are archived before removal.")
+	cmd.PersistentFlags().String(cfg.KeyArchiveFormat, cfg.ArchiveTar, fmt.Sprintf("Archive format: tar keeps the whole directory, bundle keeps only the committed history and stashes. Allowed values: [%s].", strings.Join(cfg.AllowedArchive, ", ")))
+	cmd.PersistentFlags().BoolP(cfg.KeyDryRun, "n", false, "Only print the plan.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultHost, "t", cfg.Defaults[cfg.KeyDefaultHost], "Host to use when a repo in the manifest doesn't have a specified host.")
+	cmd.PersistentFlags().StringP(cfg.KeyDefaultScheme, "c", cfg.Defaults[cfg.KeyDefaultScheme], "Scheme to use when a repo in the manifest doesn't have a specified scheme.")
+	cmd.PersistentFlags().Bool(cfg.KeyPrune, false, "Remove repositories which are not in the manifest, unless they have work which would be lost.")
+	cmd.PersistentFlags().BoolP(cfg.KeySkipHost, "s", false, "Don't create a directory for host.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are synced.")
+	cmd.PersistentFlags().StringSlice(cfg.KeyTag, nil, "Sync only repositories with given tag. Can be repeated to sync repositories with any of the tags.")
+	cmd.PersistentFlags().BoolP(cfg.KeyYes, "y", false, "Apply the plan without asking for confirmation.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runSyncCommand(cmd *cobra.Command, args []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+	cfg.Expand(cfg.KeyArchive)
+
+	config := &pkg.SyncCfg{
+		Archive:       viper.GetString(cfg.KeyArchive),
+		ArchiveFormat: viper.GetString(cfg.KeyArchiveFormat),
+		DefHost:       viper.GetString(cfg.KeyDefaultHost),
+		DefScheme:     viper.GetString(cfg.KeyDefaultScheme),
+		DryRun:        viper.GetBool(cfg.KeyDryRun),
+		Manifest:      args[0],
+		Prune:         viper.GetBool(cfg.KeyPrune),
+		Root:          viper.GetString(cfg.KeyReposRoot),
+		Routes:        cfg.Routes(),
+		SkipHost:      viper.GetBool(cfg.KeySkipHost),
+		Tags:          viper.GetStringSlice(cfg.KeyTag),
+		Yes:           viper.GetBool(cfg.KeyYes),
+	}
+
+	// An explicit --root flag syncs only that root, regardless of the routes.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
+	}
+
+	return pkg.Sync(config)
+}
+
+func runSync(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the sync command
+	cmd := newSyncCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
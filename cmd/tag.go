@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grdl/git-get/pkg"
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const tagExample = `  git get tag github.com/grdl/git-get backend oncall
+  git get tag --remove github.com/grdl/git-get oncall
+  git get tag github.com/grdl/git-get`
+
+func newTagCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "git get tag <REPO> [TAG]...",
+		Short:        "Add tags to a repository, remove them or print them.",
+		Long:         "Add tags to a repository, remove them or, if no tags are given, print them.\nTags are stored in the repository's git config and can be used to select repositories with the --tag flag of other commands.\nThe repository path can be given relative to the current directory or to the repos root.",
+		Example:      tagExample,
+		RunE:         runTagCommand,
+		Args:         cobra.MinimumNArgs(1),
+		Version:      cfg.Version(),
+		SilenceUsage: true, // We don't want to show usage on legit errors (eg, wrong path, repo already existing etc.)
+	}
+
+	cmd.PersistentFlags().BoolP(cfg.KeyRemove, "d", false, "Remove given tags instead of adding them.")
+	cmd.PersistentFlags().StringP(cfg.KeyReposRoot, "r", cfg.Defaults[cfg.KeyReposRoot], "Path to repos root where repositories are cloned.")
+	cmd.PersistentFlags().BoolP("help", "h", false, "Print this help and exit.")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print version and exit.")
+
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Sprintf("failed to bind flags: %v", err))
+	}
+
+	return cmd
+}
+
+func runTagCommand(cmd *cobra.Command, args []string) error {
+	cfg.Expand(cfg.KeyReposRoot)
+
+	config := &pkg.TagCfg{
+		Path:   args[0],
+		Remove: viper.GetBool(cfg.KeyRemove),
+		Root:   viper.GetString(cfg.KeyReposRoot),
+		Routes: cfg.Routes(),
+		Tags:   args[1:],
+	}
+
+	// An explicit --root flag resolves the repo path only against that root, regardless of the routes.
+	if cmd.Flags().Changed(cfg.KeyReposRoot) {
+		config.Routes = nil
+	}
+
+	return pkg.Tag(config)
+}
+
+func runTag(args []string) {
+	// Initialize configuration
+	cfg.Init(&git.Config{})
+
+	// Create and execute the tag command
+	cmd := newTagCommand()
+
+	// Set args for cobra to parse
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
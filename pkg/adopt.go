@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+)
+
+// AdoptCfg provides configuration for the Adopt command.
+type AdoptCfg struct {
+	DefHost    string
+	DefScheme  string
+	DryRun     bool
+	Paths      []string
+	PruneEmpty bool // Remove all parent directories of the moved repos left empty, up to the home directory.
+	Root       string
+	Routes     []cfg.Route // Roots other than Root for repos matching their patterns.
+	SkipHost   bool
+}
+
+// Adopt executes the "git get adopt" command.
+// It moves existing repos cloned outside of the repos root (or at a wrong path inside it) into the location
+// where "git get" would clone them, based on their remote URL and routes.
+func Adopt(conf *AdoptCfg) error {
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return err
+	}
+
+	l := &layout{
+		root:      root,
+		routes:    conf.Routes,
+		defHost:   conf.DefHost,
+		defScheme: conf.DefScheme,
+		skipHost:  conf.SkipHost,
+	}
+
+	var relocations []*relocation
+
+	for _, path := range conf.Paths {
+		r := adoptRelocation(l, path)
+		if r.src == r.dst {
+			fmt.Printf("%s is already in place\n", r.src)
+
+			continue
+		}
+
+		relocations = append(relocations, r)
+	}
+
+	planRelocations(relocations)
+
+	stop := filepath.Dir
+	if conf.PruneEmpty {
+		stop = adoptStop
+	}
+
+	return relocate(relocations, conf.DryRun, stop)
+}
+
+// adoptRelocation opens the repo at path and finds its target from the remote URL.
+func adoptRelocation(l *layout, path string) *relocation {
+	r := &relocation{src: path}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		r.err = err
+
+		return r
+	}
+
+	r.src = filepath.Clean(abs)
+
+	if _, err := os.Stat(filepath.Join(r.src, ".git")); err != nil {
+		r.err = errNotARepo
+
+		return r
+	}
+
+	if r.repo, r.err = git.Open(r.src); r.err != nil {
+		return r
+	}
+
+	remote, err := r.repo.Remote()
+	if err != nil {
+		r.err = err
+
+		return r
+	}
+
+	r.dst, r.err = l.canonicalPath(remote)
+
+	return r
+}
+
+// adoptStop returns the directory where the cleanup of emptied source directories stops when --prune-empty is used.
+// Directories are cleaned up only inside the home directory, so eg ~/src is removed once it's empty.
+// For repos outside of it, none of the parent directories are removed.
+// Without --prune-empty the cleanup stops at the parent of the repo, so no directories other than the repo are removed.
+func adoptStop(src string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || !isInside(home, src) {
+		return filepath.Dir(src)
+	}
+
+	return home
+}
@@ -0,0 +1,119 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/grdl/git-get/pkg/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdopt(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	src := test.TempDir(t, "")
+
+	foo := repoWithRemote(t, src, "foo", "https://github.com/grdl/foo.git")
+	fooCopy := repoWithRemote(t, src, "foo-copy", "git@github.com:grdl/foo.git")
+	bar := repoWithRemote(t, src, "bar", "https://gitlab.com/grdl/bar")
+	taken := repoWithRemote(t, src, "taken", "https://github.com/grdl/taken")
+	noRemote := repoWithRemote(t, src, "no-remote", "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "github.com", "grdl", "taken"), 0755))
+
+	conf := &AdoptCfg{
+		DefHost:   "github.com",
+		DefScheme: "ssh",
+		DryRun:    true,
+		Paths:     []string{foo, fooCopy, bar, taken, noRemote},
+		Root:      root,
+	}
+
+	err := Adopt(conf)
+	require.ErrorIs(t, err, errTargetTaken)
+	require.ErrorIs(t, err, errTargetExists)
+	require.ErrorIs(t, err, errNoRemote)
+	assert.DirExists(t, foo)
+	assert.DirExists(t, bar)
+
+	conf.DryRun = false
+	require.Error(t, Adopt(conf))
+
+	assert.DirExists(t, filepath.Join(root, "github.com", "grdl", "foo", ".git"))
+	assert.DirExists(t, filepath.Join(root, "gitlab.com", "grdl", "bar", ".git"))
+	assert.NoDirExists(t, foo)
+	assert.NoDirExists(t, bar)
+	assert.DirExists(t, fooCopy)
+	assert.DirExists(t, taken)
+	assert.DirExists(t, noRemote)
+
+	// Adopting a repo which is already in place does nothing.
+	conf.Paths = []string{filepath.Join(root, "github.com", "grdl", "foo")}
+	require.NoError(t, Adopt(conf))
+	assert.DirExists(t, filepath.Join(root, "github.com", "grdl", "foo", ".git"))
+}
+
+func TestAdoptKeepsSourceParent(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	parent := filepath.Join(test.TempDir(t, ""), "old", "clones")
+	require.NoError(t, os.MkdirAll(parent, 0755))
+
+	foo := repoWithRemote(t, parent, "foo", "https://github.com/grdl/foo.git")
+
+	conf := &AdoptCfg{
+		DefHost:   "github.com",
+		DefScheme: "ssh",
+		Paths:     []string{foo},
+		Root:      root,
+	}
+
+	require.NoError(t, Adopt(conf))
+	assert.DirExists(t, filepath.Join(root, "github.com", "grdl", "foo", ".git"))
+	assert.NoDirExists(t, foo)
+	assert.DirExists(t, parent, "emptied parent directories are removed only with --prune-empty")
+}
+
+func TestAdoptRoutes(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+	src := test.TempDir(t, "")
+
+	api := repoWithRemote(t, src, "api", "git@github.com:mycompany/api.git")
+	dotfiles := repoWithRemote(t, src, "dotfiles", "git@github.com:grdl/dotfiles.git")
+
+	conf := &AdoptCfg{
+		DefHost:   "github.com",
+		DefScheme: "ssh",
+		Paths:     []string{api, dotfiles},
+		Root:      root,
+		Routes:    []cfg.Route{{Pattern: "github.com/mycompany/*", Root: work}},
+	}
+
+	require.NoError(t, Adopt(conf))
+	assert.DirExists(t, filepath.Join(work, "github.com", "mycompany", "api", ".git"))
+	assert.DirExists(t, filepath.Join(root, "github.com", "grdl", "dotfiles", ".git"))
+	assert.NoDirExists(t, filepath.Join(root, "github.com", "mycompany"))
+}
+
+// repoWithRemote creates a repo inside the parent dir with the origin remote pointing at given url.
+func repoWithRemote(t *testing.T, parent string, name string, url string) string {
+	t.Helper()
+
+	path := filepath.Join(parent, name)
+	require.NoError(t, os.Rename(test.RepoWithCommit(t).Path(), path))
+
+	if url != "" {
+		require.NoError(t, run.Git("remote", "add", "origin", url).OnRepo(path).AndShutUp())
+	}
+
+	return path
+}
@@ -0,0 +1,202 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/forge"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/run"
+)
+
+var errBrowseNoBranch = errors.New("--branch requires a local repository")
+
+// Gitconfig key of the per-host template of repos' web URLs, eg "gitget.git.example.com.web-url".
+const keyWebURL = "web-url"
+
+// BrowseCfg provides configuration for the Browse command.
+type BrowseCfg struct {
+	Branch    bool // Point at the current branch.
+	Browser   string
+	Commit    string
+	DefHost   string
+	DefScheme string
+	File      string // Path of a file relative to the repo root.
+	Print     bool
+	Root      string
+	Target    string // Path of a repo or a repo URL. The repo in the current directory is used if empty.
+}
+
+// Browse executes the "git get browse" command.
+// It converts the remote URL of a repo into the web URL on the forge hosting it and opens it in a browser or prints it.
+func Browse(conf *BrowseCfg) error {
+	webURL, err := browseURL(conf, cfg.WithHosts(&git.Config{}))
+	if err != nil {
+		return err
+	}
+
+	if conf.Print {
+		fmt.Println(webURL)
+
+		return nil
+	}
+
+	return openBrowser(conf.Browser, webURL)
+}
+
+// browseURL returns the web URL of the target repo, and of its branch, commit or file if requested.
+func browseURL(conf *BrowseCfg, gitconfig cfg.Gitconfig) (string, error) {
+	remote, target, err := browseTarget(conf)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := ParseURL(remote, conf.DefHost, conf.DefScheme)
+	if err != nil {
+		return "", err
+	}
+
+	// Config keys and forge detection use the host without a port.
+	host := url.Hostname()
+
+	// The port of an ssh remote is the ssh port, eg ssh://git@github.com:22/grdl/git-get is browsed at https://github.com/grdl/git-get.
+	// The port of an http(s) remote is the port of the web server too, so it's kept.
+	webHost := host
+	if url.Scheme == "http" || url.Scheme == "https" {
+		webHost = url.Host
+	}
+
+	key := func(name string) string {
+		return fmt.Sprintf("%s.%s.%s", cfg.GitgetPrefix, host, name)
+	}
+
+	base := gitconfig.Get(key(keyWebURL))
+	if base == "" {
+		base = forge.DefaultWebBase
+	}
+
+	base = strings.NewReplacer("{host}", webHost, "{path}", URLToPath(*url, true)).Replace(base)
+
+	name := gitconfig.Get(key(cfg.KeyForge))
+	if name == "" {
+		name = forge.ForHost(host)
+	}
+
+	return forge.WebURL(name, base, target), nil
+}
+
+// browseTarget returns the remote URL of the browsed repo and what to point at in it.
+// Targets which aren't paths of local repos are treated as repo URLs, eg "grdl/git-get".
+func browseTarget(conf *BrowseCfg) (string, forge.WebTarget, error) {
+	target := forge.WebTarget{Commit: conf.Commit, File: filepath.ToSlash(conf.File)}
+
+	path, err := localRepoPath(conf)
+	if err != nil {
+		return "", target, err
+	}
+
+	if path == "" {
+		if conf.Branch {
+			return "", target, errBrowseNoBranch
+		}
+
+		// Without a local repo, files are shown at the default branch.
+		if target.File != "" && target.Commit == "" {
+			target.Branch = "HEAD"
+		}
+
+		return conf.Target, target, nil
+	}
+
+	repo, err := git.Open(path)
+	if err != nil {
+		return "", target, err
+	}
+
+	remote, err := repo.Remote()
+	if err != nil {
+		return "", target, err
+	}
+
+	if remote == "" {
+		return "", target, fmt.Errorf("%w: %s", errNoRemote, path)
+	}
+
+	if target.Commit != "" {
+		if target.Commit, err = repo.ResolveCommit(target.Commit); err != nil {
+			return "", target, err
+		}
+	}
+
+	if conf.Branch || (target.File != "" && target.Commit == "") {
+		branch, err := repo.CurrentBranch()
+		if err != nil {
+			return "", target, err
+		}
+
+		// On a detached HEAD, point at the checked out commit instead.
+		if branch == "HEAD" {
+			if target.Commit, err = repo.ResolveCommit(branch); err != nil {
+				return "", target, err
+			}
+		} else {
+			target.Branch = branch
+		}
+	}
+
+	return remote, target, nil
+}
+
+// localRepoPath returns the path of the target repo if it's a local repo, or an empty string if the target should be parsed as an URL.
+// Without a target, it's the repo containing the current directory.
+func localRepoPath(conf *BrowseCfg) (string, error) {
+	if conf.Target == "" {
+		return run.Git("rev-parse", "--show-toplevel").AndCaptureLine()
+	}
+
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return "", err
+	}
+
+	path := resolveRepoPath([]string{root}, conf.Target)
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return "", nil //nolint:nilerr // Not a local repo, so it's parsed as an URL.
+	}
+
+	return path, nil
+}
+
+// openBrowser opens the URL in the given browser command or, if it's empty, in the one from the BROWSER env var
+// or the default browser of the system.
+func openBrowser(browser string, url string) error {
+	if browser == "" {
+		browser = os.Getenv("BROWSER")
+	}
+
+	var args []string
+
+	switch {
+	case browser != "":
+		args = strings.Fields(browser)
+	case runtime.GOOS == "darwin":
+		args = []string{"open"}
+	case runtime.GOOS == "windows":
+		args = []string{"rundll32", "url.dll,FileProtocolHandler"}
+	default:
+		args = []string{"xdg-open"}
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], url)...) //nolint:gosec // The browser is configured by the user.
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s in browser %s: %w", url, args[0], err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/grdl/git-get/pkg/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowseURL(t *testing.T) {
+	t.Parallel()
+
+	gitconfig := fakeGitconfig{
+		"gitget.git.example.com.forge":   "gitlab",
+		"gitget.git.example.com.web-url": "https://{host}/code/{path}",
+	}
+
+	tests := []struct {
+		name string
+		conf *BrowseCfg
+		want string
+	}{
+		{
+			name: "short name",
+			conf: &BrowseCfg{Target: "grdl/git-get"},
+			want: "https://github.com/grdl/git-get",
+		}, {
+			name: "scp syntax with .git suffix",
+			conf: &BrowseCfg{Target: "git@github.com:grdl/git-get.git"},
+			want: "https://github.com/grdl/git-get",
+		}, {
+			name: "ssh with port",
+			conf: &BrowseCfg{Target: "ssh://git@gitlab.com:2222/grdl/sub/project.git"},
+			want: "https://gitlab.com/grdl/sub/project",
+		}, {
+			name: "https with port",
+			conf: &BrowseCfg{Target: "https://git.example.com:8443/team/repo.git"},
+			want: "https://git.example.com:8443/code/team/repo",
+		}, {
+			name: "https with port and default template",
+			conf: &BrowseCfg{Target: "https://code.example.org:8443/team/repo"},
+			want: "https://code.example.org:8443/team/repo",
+		}, {
+			name: "commit",
+			conf: &BrowseCfg{Target: "https://gitlab.com/grdl/project", Commit: "abc123"},
+			want: "https://gitlab.com/grdl/project/-/commit/abc123",
+		}, {
+			name: "file without local repo is shown at the default branch",
+			conf: &BrowseCfg{Target: "https://codeberg.org/grdl/project.git", File: "docs/README.md"},
+			want: "https://codeberg.org/grdl/project/src/branch/HEAD/docs/README.md",
+		}, {
+			name: "host template",
+			conf: &BrowseCfg{Target: "git@git.example.com:team/project.git", File: "main.go", Commit: "abc123"},
+			want: "https://git.example.com/code/team/project/-/blob/abc123/main.go",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.conf.Root = t.TempDir()
+			test.conf.DefHost = "github.com"
+			test.conf.DefScheme = "ssh"
+
+			got, err := browseURL(test.conf, gitconfig)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	_, err := browseURL(&BrowseCfg{Target: "grdl/git-get", Branch: true, Root: t.TempDir()}, gitconfig)
+	assert.ErrorIs(t, err, errBrowseNoBranch)
+}
+
+func TestBrowseLocalRepo(t *testing.T) {
+	t.Parallel()
+
+	repo := test.RepoWithCommit(t)
+	require.NoError(t, run.Git("remote", "add", "origin", "git@github.com:grdl/git-get.git").OnRepo(repo.Path()).AndShutUp())
+
+	commit, err := run.Git("rev-parse", "HEAD").OnRepo(repo.Path()).AndCaptureLine()
+	require.NoError(t, err)
+
+	branch, err := run.Git("rev-parse", "--abbrev-ref", "HEAD").OnRepo(repo.Path()).AndCaptureLine()
+	require.NoError(t, err)
+
+	conf := &BrowseCfg{Target: repo.Path(), Root: t.TempDir()}
+
+	got, err := browseURL(conf, fakeGitconfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/grdl/git-get", got)
+
+	conf.Branch = true
+	got, err = browseURL(conf, fakeGitconfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/grdl/git-get/tree/"+branch, got)
+
+	conf.Branch, conf.File = false, "README.md"
+	got, err = browseURL(conf, fakeGitconfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/grdl/git-get/blob/"+branch+"/README.md", got)
+
+	conf.File, conf.Commit = "", "HEAD"
+	got, err = browseURL(conf, fakeGitconfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/grdl/git-get/commit/"+commit, got)
+
+	conf.Commit = "nonexistent"
+	_, err = browseURL(conf, fakeGitconfig{})
+	assert.Error(t, err)
+
+	_, err = browseURL(&BrowseCfg{Target: test.RepoWithCommit(t).Path()}, fakeGitconfig{})
+	assert.ErrorIs(t, err, errNoRemote)
+}
@@ -1,9 +1,8 @@
 // Package cfg provides common configuration to all commands.
-// It contains config key names, default values and provides methods to read values from global gitconfig file.
+// It contains config key names, default values and provides methods to read values from config files and gitconfig.
 package cfg
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,20 +16,60 @@ const GitgetPrefix = "gitget"
 
 // CLI flag keys.
 var (
+	KeyAPIURL        = "api-url"
+	KeyArchive       = "archive"
+	KeyArchiveFormat = "archive-format"
+	KeyArchived      = "archived"
 	KeyBranch        = "branch"
+	KeyBrowser       = "browser"
+	KeyClone         = "clone"
+	KeyCommit        = "commit"
+	KeyDaemon        = "daemon"
+	KeyDiskUsage     = "disk-usage"
 	KeyDump          = "dump"
+	KeyDryRun        = "dry-run"
 	KeyDefaultHost   = "host"
+	KeyExclude       = "exclude"
 	KeyFetch         = "fetch"
+	KeyFile          = "file"
+	KeyFetchInterval = "fetch-interval"
+	KeyFix           = "fix"
+	KeyForce         = "force"
+	KeyForge         = "forge"
+	KeyForks         = "forks"
+	KeyGroupBy       = "group-by"
+	KeyInclude       = "include"
+	KeyInterval      = "interval"
+	KeyLastCommit    = "last-commit"
+	KeyMisplaced     = "misplaced"
+	KeyOlderThan     = "older-than"
 	KeyOutput        = "out"
+	KeyPrint         = "print"
+	KeyProfile       = "profile"
+	KeyPrune         = "prune"
+	KeyPruneEmpty    = "prune-empty"
+	KeyPullRequests  = "pull-requests"
+	KeyRemove        = "remove"
 	KeyDefaultScheme = "scheme"
 	KeySkipHost      = "skip-host"
+	KeySocket        = "socket"
+	KeySort          = "sort"
+	KeyStaleDays     = "stale-days"
+	KeyTag           = "tag"
+	KeyToken         = "token"
+	KeyTrackUpstream = "track-upstream"
+	KeyReport        = "report"
 	KeyReposRoot     = "root"
+	KeyUpstream      = "upstream"
+	KeyWatch         = "watch"
+	KeyYes           = "yes"
 )
 
 // Defaults is a map of default values for config keys.
 var Defaults = map[string]string{
 	KeyDefaultHost:   "github.com",
 	KeyOutput:        OutTree,
+	KeySort:          SortPath,
 	KeyReposRoot:     fmt.Sprintf("~%c%s", filepath.Separator, "repositories"),
 	KeyDefaultScheme: "ssh",
 }
@@ -45,6 +84,39 @@ const (
 // AllowedOut are allowed values for the --out flag.
 var AllowedOut = []string{OutDump, OutFlat, OutTree}
 
+// Values for the --archive-format flag.
+const (
+	ArchiveBundle = "bundle"
+	ArchiveTar    = "tar"
+)
+
+// AllowedArchive are allowed values for the --archive-format flag.
+var AllowedArchive = []string{ArchiveBundle, ArchiveTar}
+
+// Values for the --group-by flag.
+const (
+	GroupPath = "path"
+	GroupTag  = "tag"
+)
+
+// AllowedGroupBy are allowed values for the --group-by flag.
+var AllowedGroupBy = []string{GroupPath, GroupTag}
+
+// Values for the --sort flag.
+const (
+	SortAge    = "age"
+	SortAhead  = "ahead"
+	SortBehind = "behind"
+	SortDate   = "date"
+	SortDirty  = "dirty"
+	SortHost   = "host"
+	SortPath   = "path"
+	SortSize   = "size"
+)
+
+// AllowedSort are allowed values for the --sort flag.
+var AllowedSort = []string{SortAge, SortAhead, SortBehind, SortDate, SortDirty, SortHost, SortPath, SortSize}
+
 // Version metadata set by ldflags during the build.
 var (
 	version string
@@ -67,40 +139,45 @@ func Version() string {
 
 // Gitconfig represents gitconfig file.
 type Gitconfig interface {
+	// Get returns the value of a key or an empty string if it's missing.
 	Get(key string) string
+	// Values returns all "gitget.*" keys with their values.
+	Values() map[string]string
 }
 
-// Init initializes viper config registry. Values are looked up in the following order: cli flag, env variable, gitconfig file, default value.
+// Init initializes viper config registry. Values are looked up in the following order: cli flag, env variable,
+// project config file, user config file, gitconfig file, default value.
 func Init(cfg Gitconfig) {
-	readGitconfig(cfg)
-
 	viper.SetEnvPrefix(strings.ToUpper(GitgetPrefix))
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
-}
 
-// readGitConfig loads values from gitconfig file into viper's registry.
-// Viper doesn't support the gitconfig format so we load it using "git config --global" command and populate a temporary "env" string,
-// which is then feed to Viper.
-func readGitconfig(cfg Gitconfig) {
-	var lines []string
+	load(cfg, UserConfigFile(), ProjectConfigFile())
+}
 
-	// TODO: Can we somehow iterate over all possible flags?
-	for key := range Defaults {
-		if val := cfg.Get(fmt.Sprintf("%s.%s", GitgetPrefix, key)); val != "" {
-			lines = append(lines, fmt.Sprintf("%s=%s", key, val))
+// readGitconfig loads values of all "gitget.<key>" keys from gitconfig into the settings.
+// Per-host keys, eg "gitget.<host>.token", are not config keys and are read from gitconfig when needed.
+func readGitconfig(cfg Gitconfig, settings map[string]setting) {
+	for name, value := range cfg.Values() {
+		key, ok := strings.CutPrefix(name, GitgetPrefix+".")
+		if !ok || strings.Contains(key, ".") {
+			continue
 		}
-	}
-
-	viper.SetConfigType("env")
 
-	if err := viper.ReadConfig(bytes.NewBufferString(strings.Join(lines, "\n"))); err != nil {
-		// Log error but don't fail - configuration is optional
-		fmt.Fprintf(os.Stderr, "Warning: failed to read git config: %v\n", err)
+		settings[key] = setting{value: gitBool(value), source: SourceGitconfig}
 	}
+}
 
-	// TODO: A hacky way to read boolean flag from gitconfig. Find a cleaner way.
-	if val := cfg.Get(fmt.Sprintf("%s.%s", GitgetPrefix, KeySkipHost)); strings.ToLower(val) == "true" {
-		viper.Set(KeySkipHost, true)
+// gitBool converts boolean values accepted by git but not by viper, eg "yes" or "off", into "true" or "false".
+// Other values are returned unchanged.
+func gitBool(value string) string {
+	switch strings.ToLower(value) {
+	case "yes", "on":
+		return "true"
+	case "no", "off":
+		return "false"
+	default:
+		return value
 	}
 }
 
@@ -108,10 +185,19 @@ func readGitconfig(cfg Gitconfig) {
 // If expansion fails or is not needed, the config is not modified.
 func Expand(key string) {
 	path := viper.GetString(key)
+	if expanded := expandHome(path); expanded != path {
+		viper.Set(key, expanded)
+	}
+}
+
+// expandHome replaces the leading "~" of a path with the user's home directory.
+// If expansion fails or is not needed, the path is returned unchanged.
+func expandHome(path string) string {
 	if strings.HasPrefix(path, "~") {
 		if homeDir, err := os.UserHomeDir(); err == nil {
-			expanded := filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
-			viper.Set(key, expanded)
+			return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
 		}
 	}
+
+	return path
 }
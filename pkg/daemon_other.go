@@ -0,0 +1,10 @@
+//go:build !unix
+
+package pkg
+
+import "io/fs"
+
+// ownedByUser checks if the file is owned by the current user. File owners aren't checked on this platform.
+func ownedByUser(fs.FileInfo) bool {
+	return true
+}
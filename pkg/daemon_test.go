@@ -0,0 +1,129 @@
+package pkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemon(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	repo := test.RepoWithUncommittedAndUntracked(t)
+	require.NoError(t, os.Rename(repo.Path(), filepath.Join(root, "repo")))
+
+	live := &liveStatuses{roots: []string{root}}
+	live.reload(context.Background(), false)
+	defer live.stopEvents()
+
+	socket := testSocket(t)
+
+	listener, err := listen(socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go serve(listener, root, live)
+
+	statuses, err := queryDaemon(socket, root)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, filepath.Join(root, "repo"), statuses[0].Path())
+	assert.Equal(t, "main", statuses[0].Current())
+	assert.Equal(t, "1 uncommitted 1 untracked", statuses[0].WorkTreeStatus())
+
+	_, err = queryDaemon(socket, filepath.Join(root, "other"))
+	require.Error(t, err)
+
+	// Relative roots are resolved against the current directory of the client.
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	rel, err := filepath.Rel(wd, root)
+	require.NoError(t, err)
+
+	statuses, err = queryDaemon(socket, rel)
+	require.NoError(t, err)
+	assert.Len(t, statuses, 1)
+
+	_, err = listen(socket)
+	require.ErrorIs(t, err, ErrDaemonRunning)
+}
+
+func TestLiveStatusesRestartWatcher(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	repo := test.RepoWithUncommittedAndUntracked(t)
+	require.NoError(t, os.Rename(repo.Path(), filepath.Join(root, "repo")))
+
+	live := &liveStatuses{roots: []string{root}}
+	live.reload(context.Background(), false)
+	defer live.stopEvents()
+
+	require.NotNil(t, live.updates)
+
+	// A closed updates channel means the watcher failed.
+	live.merge(nil, false)
+	assert.Nil(t, live.updates)
+	assert.Empty(t, live.repoPaths)
+
+	live.reload(context.Background(), false)
+	assert.NotNil(t, live.updates)
+	assert.Equal(t, []string{filepath.Join(root, "repo")}, live.repoPaths)
+}
+
+func TestQueryDaemonNotRunning(t *testing.T) {
+	t.Parallel()
+
+	_, err := queryDaemon(testSocket(t), "/some/root")
+	assert.Error(t, err)
+}
+
+func TestListenRemovesStaleSocket(t *testing.T) {
+	t.Parallel()
+
+	socket := testSocket(t)
+	require.NoError(t, os.WriteFile(socket, nil, 0600))
+
+	listener, err := listen(socket)
+	require.NoError(t, err)
+	listener.Close()
+}
+
+func TestQueryDaemonUntrustedSocket(t *testing.T) {
+	t.Parallel()
+
+	// Eg, a file created by another user in place of the socket.
+	socket := testSocket(t)
+	require.NoError(t, os.WriteFile(socket, nil, 0600))
+
+	_, err := queryDaemon(socket, "/some/root")
+	assert.ErrorIs(t, err, errUntrustedSocket)
+}
+
+func TestListenCreatesPrivateDir(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(test.TempDir(t, ""), "git-get", "s.sock")
+
+	listener, err := listen(socket)
+	require.NoError(t, err)
+	listener.Close()
+
+	info, err := os.Stat(filepath.Dir(socket))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+}
+
+// testSocket returns a path for a Unix socket. Socket paths are limited to ~100 chars so t.TempDir() might be too long.
+func testSocket(t *testing.T) string {
+	t.Helper()
+
+	return filepath.Join(test.TempDir(t, ""), "s.sock")
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package pkg
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ownedByUser checks if the file is owned by the current user.
+func ownedByUser(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	return ok && int(stat.Uid) == os.Getuid()
+}
@@ -0,0 +1,171 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/forge"
+	"github.com/grdl/git-get/pkg/git"
+)
+
+var errInvalidPattern = errors.New("invalid pattern")
+
+// tokenEnvVars are the env vars commonly used for access tokens of each forge. They are used when no token is configured.
+var tokenEnvVars = map[string]string{
+	forge.GitHub: "GITHUB_TOKEN",
+	forge.GitLab: "GITLAB_TOKEN",
+	forge.Gitea:  "GITEA_TOKEN",
+}
+
+// DiscoverCfg provides configuration for the Discover command.
+type DiscoverCfg struct {
+	APIURL    string
+	Archived  bool // Include archived repos.
+	Clone     bool
+	DefHost   string
+	DefScheme string
+	Exclude   []string
+	Forge     string
+	Forks     bool // Include forks.
+	Include   []string
+	Owner     string
+	Root      string
+	Routes    []cfg.Route // Roots other than Root for repos matching their patterns.
+	SkipHost  bool
+	Token     string
+}
+
+// Discover executes the "git get discover" command.
+// It lists all repos of an organization, group or user from a forge API and either prints them as a dump file
+// or clones the ones which don't exist under the root yet.
+func Discover(conf *DiscoverCfg) error {
+	if err := validatePatterns(conf.Include); err != nil {
+		return err
+	}
+
+	if err := validatePatterns(conf.Exclude); err != nil {
+		return err
+	}
+
+	token := conf.Token
+	if token == "" {
+		token = os.Getenv(tokenEnvVars[conf.Forge])
+	}
+
+	provider, err := forge.New(conf.Forge, forge.Config{BaseURL: conf.APIURL, Token: token})
+	if err != nil {
+		return err
+	}
+
+	repos, err := provider.ListRepos(context.Background(), conf.Owner)
+	if err != nil {
+		return fmt.Errorf("failed listing repositories of %s: %w", conf.Owner, err)
+	}
+
+	repos = filterForgeRepos(repos, conf)
+
+	if !conf.Clone {
+		for _, repo := range repos {
+			fmt.Println(cloneURL(repo, conf.DefScheme))
+		}
+
+		return nil
+	}
+
+	return cloneForgeRepos(repos, conf)
+}
+
+// validatePatterns checks if the include and exclude patterns are valid glob patterns.
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w %q: %w", errInvalidPattern, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// filterForgeRepos returns the repos matching any of the include patterns (or all repos if there are none) and none of the exclude patterns.
+// Archived repos and forks are skipped unless they are explicitly requested.
+func filterForgeRepos(repos []forge.Repo, conf *DiscoverCfg) []forge.Repo {
+	var filtered []forge.Repo
+
+	for _, repo := range repos {
+		if repo.Archived && !conf.Archived || repo.Fork && !conf.Forks {
+			continue
+		}
+
+		if len(conf.Include) > 0 && !matchesAny(repo.Name, conf.Include) {
+			continue
+		}
+
+		if matchesAny(repo.Name, conf.Exclude) {
+			continue
+		}
+
+		filtered = append(filtered, repo)
+	}
+
+	return filtered
+}
+
+// matchesAny checks if a repo name matches any of the glob patterns. Patterns are already validated, so match errors are ignored.
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cloneURL returns the ssh URL of the repo if the scheme is ssh or the http URL otherwise.
+func cloneURL(repo forge.Repo, scheme string) string {
+	if scheme == "ssh" && repo.SSHURL != "" {
+		return repo.SSHURL
+	}
+
+	return repo.HTTPURL
+}
+
+// cloneForgeRepos clones the repos which don't exist under the root (or the root of their route) yet. It continues when a clone fails and returns the errors of all failed clones.
+func cloneForgeRepos(repos []forge.Repo, conf *DiscoverCfg) error {
+	var errs []error
+
+	for _, repo := range repos {
+		url, err := ParseURL(cloneURL(repo, conf.DefScheme), conf.DefHost, conf.DefScheme)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		opts := &git.CloneOpts{
+			URL:  url,
+			Path: filepath.Join(routeRoot(conf.Root, conf.Routes, url), URLToPath(*url, conf.SkipHost)),
+		}
+
+		if exists, _ := git.Exists(opts.Path); exists {
+			continue
+		}
+
+		fmt.Printf("Cloning %s...\n", url.String())
+
+		if _, err := git.Clone(opts); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clone %s: %w", repo.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed cloning repositories:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
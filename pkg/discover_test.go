@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/forge"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterForgeRepos(t *testing.T) {
+	t.Parallel()
+
+	repos := []forge.Repo{
+		{Name: "git-get"},
+		{Name: "git-list"},
+		{Name: "homebrew-tap"},
+		{Name: "old", Archived: true},
+		{Name: "fork", Fork: true},
+		{Name: "sub/project"},
+	}
+
+	tests := []struct {
+		name string
+		conf *DiscoverCfg
+		want []string
+	}{
+		{
+			name: "defaults skip archived and forks",
+			conf: &DiscoverCfg{},
+			want: []string{"git-get", "git-list", "homebrew-tap", "sub/project"},
+		}, {
+			name: "archived and forks",
+			conf: &DiscoverCfg{Archived: true, Forks: true},
+			want: []string{"git-get", "git-list", "homebrew-tap", "old", "fork", "sub/project"},
+		}, {
+			name: "include",
+			conf: &DiscoverCfg{Include: []string{"git-*", "sub/*"}},
+			want: []string{"git-get", "git-list", "sub/project"},
+		}, {
+			name: "include and exclude",
+			conf: &DiscoverCfg{Include: []string{"git-*"}, Exclude: []string{"*-list"}},
+			want: []string{"git-get"},
+		}, {
+			name: "exclude",
+			conf: &DiscoverCfg{Exclude: []string{"homebrew-*", "sub/*"}},
+			want: []string{"git-get", "git-list"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var names []string
+			for _, repo := range filterForgeRepos(repos, test.conf) {
+				names = append(names, repo.Name)
+			}
+
+			assert.Equal(t, test.want, names)
+		})
+	}
+}
+
+func TestDiscoverAndClone(t *testing.T) {
+	t.Parallel()
+
+	first := test.RepoWithCommit(t)
+	second := test.RepoWithCommit(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/grdl/repos" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		json.NewEncoder(w).Encode([]map[string]any{ //nolint:errcheck
+			{"full_name": "grdl/first", "clone_url": "file://" + first.Path()},
+			{"full_name": "grdl/second", "clone_url": "file://" + second.Path()},
+			{"full_name": "grdl/archived", "clone_url": "file:///nonexistent", "archived": true},
+		})
+	}))
+	defer server.Close()
+
+	root := test.TempDir(t, "")
+	conf := &DiscoverCfg{
+		APIURL:    server.URL,
+		Clone:     true,
+		DefScheme: "https",
+		Exclude:   []string{"second"},
+		Forge:     forge.GitHub,
+		Owner:     "grdl",
+		Root:      root,
+	}
+
+	require.NoError(t, Discover(conf))
+	assert.DirExists(t, filepath.Join(root, URLToPath(url.URL{Path: first.Path()}, false), ".git"))
+	assert.NoDirExists(t, filepath.Join(root, URLToPath(url.URL{Path: second.Path()}, false)))
+
+	// Existing repos are skipped.
+	conf.Exclude = nil
+	require.NoError(t, Discover(conf))
+	assert.DirExists(t, filepath.Join(root, URLToPath(url.URL{Path: second.Path()}, false), ".git"))
+
+	conf.Include = []string{"["}
+	assert.ErrorIs(t, Discover(conf), errInvalidPattern)
+
+	conf.Include, conf.Owner = nil, "nobody"
+	assert.ErrorIs(t, Discover(conf), forge.ErrAPI)
+}
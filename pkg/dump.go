@@ -5,27 +5,78 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/grdl/git-get/pkg/git"
 )
 
 var (
 	errInvalidNumberOfElements = errors.New("more than two space-separated 2 elements on the line")
 	errEmptyLine               = errors.New("empty line")
+	errUnknownOption           = errors.New("unknown option")
+	errInvalidOption           = errors.New("invalid option")
+	errIncludeCycle            = errors.New("dump file includes itself")
+	errUndefinedVar            = errors.New("undefined environment variable")
+)
+
+// Directive including another dump file, eg "include team/backend.txt". Relative paths are relative to the including file.
+const includeDirective = "include"
+
+// varSyntax matches environment variables like "${GITLAB_HOST}".
+var varSyntax = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Dump file options.
+const (
+	branchOption   = "branch"   // Branch (or tag) to checkout, same as the second element of the line.
+	pathOption     = "path"     // Path to clone into, relative to the repos root, eg "path=forks/git-get".
+	depthOption    = "depth"    // Number of commits to fetch in a shallow clone.
+	sparseOption   = "sparse"   // Comma-separated directories to checkout in a sparse checkout, eg "sparse=docs,cmd".
+	tagsOption     = "tags"     // Comma-separated tags of the repo, eg "tags=work,go".
+	remoteOption   = "remote."  // Prefix of the keys which add a remote, eg "remote.upstream=https://github.com/grdl/git-get".
+	upstreamOption = "upstream" // Shorthand for "remote.upstream=<url>".
+	trackOption    = "track"    // Name of the remote which the checked out branch should track, eg "track=upstream".
 )
 
 type parsedLine struct {
-	rawurl string
-	branch string
+	rawurl  string
+	branch  string
+	path    string
+	depth   int
+	sparse  []string
+	tags    []string
+	remotes []git.Remote
+	track   string
+}
+
+// parseDumpFile opens a given dump file and parses its content into a slice of parsedLines.
+// Lines starting with "#" are comments and "include <path>" lines parse another dump file in their place.
+// Included files (also the ones included by them) must pass the check, unless it's nil.
+func parseDumpFile(path string, check includeCheck) ([]parsedLine, error) {
+	return parseDumpFileIncluded(path, check, nil)
 }
 
-// ParseDumpFile opens a given gitgetfile and parses its content into a slice of CloneOpts.
-func parseDumpFile(path string) ([]parsedLine, error) {
+// parseDumpFileIncluded parses a dump file included by the files in the stack (the top-level file goes first).
+func parseDumpFileIncluded(path string, check includeCheck, stack []string) ([]parsedLine, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(stack, abs) {
+		return nil, fmt.Errorf("%w: %s", errIncludeCycle, strings.Join(append(stack, abs), " -> "))
+	}
+
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed opening dump file %s: %w", path, err)
 	}
 	defer file.Close()
 
+	stack = append(stack, abs)
 	scanner := bufio.NewScanner(file)
 
 	var (
@@ -36,35 +87,163 @@ func parseDumpFile(path string) ([]parsedLine, error) {
 	for scanner.Scan() {
 		line++
 
-		parsed, err := parseLine(scanner.Text())
-		if err != nil && !errors.Is(errEmptyLine, err) {
-			return nil, fmt.Errorf("failed parsing dump file line %d: %w", line, err)
+		text, err := expandVars(stripComment(scanner.Text()))
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing dump file %s line %d: %w", path, line, err)
+		}
+
+		if include, ok := includePath(text); ok {
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(path), include)
+			}
+
+			if check != nil {
+				if err := check(include); err != nil {
+					return nil, fmt.Errorf("failed parsing dump file %s line %d: %w", path, line, err)
+				}
+			}
+
+			// Errors of the included file already point to the right file and line.
+			included, err := parseDumpFileIncluded(include, check, stack)
+			if err != nil {
+				return nil, err
+			}
+
+			parsedLines = append(parsedLines, included...)
+
+			continue
+		}
+
+		parsed, err := parseLine(text)
+		if errors.Is(err, errEmptyLine) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing dump file %s line %d: %w", path, line, err)
 		}
 
 		parsedLines = append(parsedLines, parsed)
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading dump file %s: %w", path, err)
+	}
+
 	return parsedLines, nil
 }
 
+// stripComment removes a comment from a line. Comments start with a "#" at the beginning of a line or after a whitespace,
+// so URLs with a fragment (eg, "https://host/repo#readme") are not cut.
+func stripComment(line string) string {
+	for i := range len(line) {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// includePath returns the path of an "include <path>" directive or false if the line isn't one.
+func includePath(line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 || fields[0] != includeDirective {
+		return "", false
+	}
+
+	return fields[1], true
+}
+
+// expandVars replaces ${VAR} with the value of the VAR environment variable. It fails if the variable isn't set,
+// because silently expanding it to an empty string would most likely clone a wrong repo.
+func expandVars(line string) (string, error) {
+	var err error
+
+	expanded := varSyntax.ReplaceAllStringFunc(line, func(match string) string {
+		name := varSyntax.FindStringSubmatch(match)[1]
+
+		value, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("%w: %s", errUndefinedVar, name)
+		}
+
+		return value
+	})
+
+	return expanded, err
+}
+
 // parseLine splits a dump file line into space-separated segments.
 // First part is the URL to clone. Second, optional, is the branch (or tag) to checkout after cloning.
+// They can be followed by "key=value" options, see the constants above for the list of them.
 func parseLine(line string) (parsedLine, error) {
 	var parsed parsedLine
 
-	if len(strings.TrimSpace(line)) == 0 {
+	parts := strings.Fields(line)
+	if len(parts) == 0 {
 		return parsed, errEmptyLine
 	}
 
-	parts := strings.Split(strings.TrimSpace(line), " ")
-	if len(parts) > 2 {
-		return parsed, errInvalidNumberOfElements
+	parsed.rawurl, parts = parts[0], parts[1:]
+
+	if len(parts) > 0 && !strings.Contains(parts[0], "=") {
+		parsed.branch, parts = parts[0], parts[1:]
 	}
 
-	parsed.rawurl = parts[0]
-	if len(parts) == 2 {
-		parsed.branch = parts[1]
+	for _, part := range parts {
+		if err := parsed.setOption(part); err != nil {
+			return parsed, err
+		}
 	}
 
 	return parsed, nil
 }
+
+func (p *parsedLine) setOption(option string) error {
+	key, value, ok := strings.Cut(option, "=")
+	if !ok {
+		return errInvalidNumberOfElements
+	}
+
+	if value == "" {
+		return fmt.Errorf("%w %q: missing value", errInvalidOption, option)
+	}
+
+	switch {
+	case key == branchOption && p.branch != "":
+		return fmt.Errorf("%w %q: branch is already set to %s", errInvalidOption, option, p.branch)
+	case key == branchOption:
+		p.branch = value
+	case key == pathOption:
+		p.path = value
+	case key == depthOption:
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 1 {
+			return fmt.Errorf("%w %q: depth must be a positive number", errInvalidOption, option)
+		}
+
+		p.depth = depth
+	case key == sparseOption:
+		p.sparse = strings.Split(value, ",")
+	case key == tagsOption:
+		tags := strings.Split(value, ",")
+		for _, tag := range tags {
+			if err := validateTag(tag); err != nil {
+				return fmt.Errorf("%w %q: %w", errInvalidOption, option, err)
+			}
+		}
+
+		p.tags = tags
+	case strings.HasPrefix(key, remoteOption) && len(key) > len(remoteOption):
+		p.remotes = append(p.remotes, git.Remote{Name: strings.TrimPrefix(key, remoteOption), URL: value})
+	case key == upstreamOption:
+		p.remotes = append(p.remotes, git.Remote{Name: upstreamRemote, URL: value})
+	case key == trackOption:
+		p.track = value
+	default:
+		return fmt.Errorf("%w %q", errUnknownOption, option)
+	}
+
+	return nil
+}
@@ -1,7 +1,14 @@
 package pkg
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParsingRefs(t *testing.T) {
@@ -59,3 +66,252 @@ func TestParsingRefs(t *testing.T) {
 		})
 	}
 }
+
+func TestParsingRemotes(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name        string
+		line        string
+		wantBranch  string
+		wantRemotes []git.Remote
+		wantTrack   string
+		wantErr     error
+	}{
+		{
+			name:        "remote without branch",
+			line:        "https://github.com/me/git-get remote.upstream=https://github.com/grdl/git-get",
+			wantRemotes: []git.Remote{{Name: "upstream", URL: "https://github.com/grdl/git-get"}},
+		},
+		{
+			name:       "branch and remotes",
+			line:       "https://github.com/me/git-get  main remote.upstream=git@github.com:grdl/git-get.git remote.other=https://gitlab.com/x/git-get",
+			wantBranch: "main",
+			wantRemotes: []git.Remote{
+				{Name: "upstream", URL: "git@github.com:grdl/git-get.git"},
+				{Name: "other", URL: "https://gitlab.com/x/git-get"},
+			},
+		},
+		{
+			name:        "upstream tracked by the branch",
+			line:        "https://github.com/me/git-get main upstream=https://github.com/grdl/git-get track=upstream",
+			wantBranch:  "main",
+			wantRemotes: []git.Remote{{Name: "upstream", URL: "https://github.com/grdl/git-get"}},
+			wantTrack:   "upstream",
+		},
+		{
+			name:    "track without remote name",
+			line:    "https://github.com/me/git-get track=",
+			wantErr: errInvalidOption,
+		},
+		{
+			name:    "branch after option",
+			line:    "https://github.com/me/git-get remote.upstream=https://github.com/grdl/git-get main",
+			wantErr: errInvalidNumberOfElements,
+		},
+		{
+			name:    "unknown option",
+			line:    "https://github.com/me/git-get foo=bar",
+			wantErr: errUnknownOption,
+		},
+		{
+			name:    "remote without name",
+			line:    "https://github.com/me/git-get remote.=https://github.com/grdl/git-get",
+			wantErr: errUnknownOption,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseLine(test.line)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantBranch, got.branch)
+			assert.Equal(t, test.wantRemotes, got.remotes)
+			assert.Equal(t, test.wantTrack, got.track)
+		})
+	}
+}
+
+func TestParsingOptions(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name    string
+		line    string
+		want    parsedLine
+		wantErr error
+	}{
+		{
+			name: "all options",
+			line: "grdl/git-get branch=dev path=forks/git-get depth=1 sparse=docs,cmd tags=work,go",
+			want: parsedLine{
+				rawurl: "grdl/git-get",
+				branch: "dev",
+				path:   "forks/git-get",
+				depth:  1,
+				sparse: []string{"docs", "cmd"},
+				tags:   []string{"work", "go"},
+			},
+		},
+		{
+			name:    "branch given twice",
+			line:    "grdl/git-get main branch=dev",
+			wantErr: errInvalidOption,
+		},
+		{
+			name:    "invalid depth",
+			line:    "grdl/git-get depth=all",
+			wantErr: errInvalidOption,
+		},
+		{
+			name:    "zero depth",
+			line:    "grdl/git-get depth=0",
+			wantErr: errInvalidOption,
+		},
+		{
+			name:    "empty tag",
+			line:    "grdl/git-get tags=work,,go",
+			wantErr: errInvalidOption,
+		},
+		{
+			name:    "trailing comma in tags",
+			line:    "grdl/git-get tags=work,",
+			wantErr: errInvalidTag,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseLine(test.line)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestPathOverride(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "github.com", "grdl"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "forks"), 0755))
+
+	inPlace := repoWithRemote(t, filepath.Join(root, "github.com", "grdl"), "git-get", "git@github.com:grdl/git-get.git")
+	misplaced := repoWithRemote(t, filepath.Join(root, "forks"), "git-get", "git@github.com:me/git-get.git")
+	outside := repoWithRemote(t, test.TempDir(t, ""), "git-get", "git@github.com:other/git-get.git")
+
+	override := pathOverride(&ListCfg{DefHost: "github.com", DefScheme: "ssh", Root: root})
+
+	for path, want := range map[string]string{inPlace: "", misplaced: "forks/git-get", outside: outside} {
+		repo, err := git.Open(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, want, override(repo.LoadStatus(git.LoadOpts{})), path)
+	}
+}
+
+func TestFetchedDumpPaths(t *testing.T) {
+	t.Parallel()
+
+	conf := &GetCfg{DefHost: "github.com", DefScheme: "ssh", Root: "/repositories", Dump: "https://example.com/repos.txt"}
+
+	tests := []struct {
+		line    parsedLine
+		want    string
+		wantErr bool
+	}{
+		{line: parsedLine{rawurl: "grdl/git-get"}, want: "/repositories/github.com/grdl/git-get"},
+		{line: parsedLine{rawurl: "grdl/git-get", path: "forks/git-get"}, want: "/repositories/forks/git-get"},
+		{line: parsedLine{rawurl: "grdl/git-get", path: "/home/me/.ssh"}, wantErr: true},
+		{line: parsedLine{rawurl: "grdl/git-get", path: "../outside"}, wantErr: true},
+		{line: parsedLine{rawurl: "grdl/git-get", path: "forks/../../outside"}, wantErr: true},
+		{line: parsedLine{rawurl: "https://github.com/../../outside"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		opts, err := dumpCloneOpts(conf, test.line, true)
+		if test.wantErr {
+			assert.ErrorIs(t, err, errPathOutsideRoot, "%+v", test.line)
+
+			continue
+		}
+
+		require.NoError(t, err, "%+v", test.line)
+		assert.Equal(t, filepath.FromSlash(test.want), opts.Path)
+	}
+
+	// Local dump files are trusted.
+	_, err := dumpCloneOpts(conf, parsedLine{rawurl: "grdl/git-get", path: "/home/me/src"}, false)
+	assert.NoError(t, err)
+}
+
+func TestParsingDumpFile(t *testing.T) {
+	t.Setenv("GITGET_TEST_HOST", "gitlab.example.com")
+
+	dir := test.TempDir(t, "")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "team"), 0755))
+
+	writeDumpFile(t, filepath.Join(dir, "repos.txt"), `# Shared repos
+https://github.com/grdl/git-get main # the tool itself
+
+include team/backend.txt
+https://github.com/grdl/git-get#readme
+`)
+	writeDumpFile(t, filepath.Join(dir, "team", "backend.txt"), `https://${GITGET_TEST_HOST}/team/api
+	# indented comment
+`)
+
+	got, err := parseDumpFile(filepath.Join(dir, "repos.txt"), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []parsedLine{
+		{rawurl: "https://github.com/grdl/git-get", branch: "main"},
+		{rawurl: "https://gitlab.example.com/team/api"},
+		{rawurl: "https://github.com/grdl/git-get#readme"},
+	}, got)
+}
+
+func TestParsingDumpFileErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := test.TempDir(t, "")
+
+	writeDumpFile(t, filepath.Join(dir, "cycle.txt"), "include other.txt\n")
+	writeDumpFile(t, filepath.Join(dir, "other.txt"), "grdl/git-get\ninclude cycle.txt\n")
+	writeDumpFile(t, filepath.Join(dir, "undefined.txt"), "# comment\nhttps://${GITGET_TEST_UNDEFINED}/grdl/git-get\n")
+	writeDumpFile(t, filepath.Join(dir, "includes-invalid.txt"), "grdl/git-get\ninclude invalid.txt\n")
+	writeDumpFile(t, filepath.Join(dir, "invalid.txt"), "\n\ngrdl/git-get depth=all\n")
+
+	_, err := parseDumpFile(filepath.Join(dir, "cycle.txt"), nil)
+	assert.ErrorIs(t, err, errIncludeCycle)
+
+	_, err = parseDumpFile(filepath.Join(dir, "undefined.txt"), nil)
+	assert.ErrorIs(t, err, errUndefinedVar)
+	assert.ErrorContains(t, err, "undefined.txt line 2")
+
+	_, err = parseDumpFile(filepath.Join(dir, "includes-invalid.txt"), nil)
+	assert.ErrorIs(t, err, errInvalidOption)
+	assert.ErrorContains(t, err, filepath.Join(dir, "invalid.txt")+" line 3")
+}
+
+func writeDumpFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
@@ -0,0 +1,63 @@
+package forge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cached is a Provider which keeps the branch infos in JSON files, so listing repos repeatedly doesn't hit the API rate limits.
+// Listing repos of an owner is not cached.
+type cached struct {
+	Provider
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	Time time.Time  `json:"time"`
+	Info BranchInfo `json:"info"`
+}
+
+// Cached wraps a Provider with a cache of branch infos stored in dir. Cached infos are used for ttl since they were loaded.
+// Errors are not cached. Dir should be different for each forge instance.
+func Cached(provider Provider, dir string, ttl time.Duration) Provider {
+	return &cached{
+		Provider: provider,
+		dir:      dir,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// BranchInfo implements the Provider interface.
+func (c *cached) BranchInfo(ctx context.Context, repo string, branch string) (BranchInfo, error) {
+	sum := sha256.Sum256([]byte(repo + "\x00" + branch))
+	file := filepath.Join(c.dir, hex.EncodeToString(sum[:8])+".json")
+
+	if content, err := os.ReadFile(file); err == nil {
+		var entry cacheEntry
+		if json.Unmarshal(content, &entry) == nil && c.now().Sub(entry.Time) < c.ttl {
+			return entry.Info, nil
+		}
+	}
+
+	info, err := c.Provider.BranchInfo(ctx, repo, branch)
+	if err != nil {
+		return info, err
+	}
+
+	// Failing to write the cache only makes the next run slower, so errors are ignored.
+	if content, err := json.Marshal(cacheEntry{Time: c.now(), Info: info}); err == nil {
+		if os.MkdirAll(c.dir, 0755) == nil {
+			os.WriteFile(file, content, 0644) //nolint:errcheck
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,71 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider returns the checks state it's set to and counts the calls.
+type fakeProvider struct {
+	checks string
+	err    error
+	calls  int
+}
+
+func (f *fakeProvider) ListRepos(context.Context, string) ([]Repo, error) {
+	return nil, nil
+}
+
+func (f *fakeProvider) BranchInfo(context.Context, string, string) (BranchInfo, error) {
+	f.calls++
+
+	return BranchInfo{Checks: f.checks}, f.err
+}
+
+func TestCached(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeProvider{checks: ChecksPending}
+	now := time.Now()
+
+	provider := Cached(fake, t.TempDir(), time.Minute).(*cached)
+	provider.now = func() time.Time { return now }
+
+	info, err := provider.BranchInfo(context.Background(), "grdl/git-get", "main")
+	require.NoError(t, err)
+	assert.Equal(t, ChecksPending, info.Checks)
+
+	fake.checks = ChecksSuccess
+
+	info, err = provider.BranchInfo(context.Background(), "grdl/git-get", "main")
+	require.NoError(t, err)
+	assert.Equal(t, ChecksPending, info.Checks, "cached info is used within ttl")
+	assert.Equal(t, 1, fake.calls)
+
+	_, err = provider.BranchInfo(context.Background(), "grdl/git-get", "other")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.calls, "each branch is cached separately")
+
+	now = now.Add(2 * time.Minute)
+
+	info, err = provider.BranchInfo(context.Background(), "grdl/git-get", "main")
+	require.NoError(t, err)
+	assert.Equal(t, ChecksSuccess, info.Checks, "expired info is reloaded")
+
+	now = now.Add(2 * time.Minute)
+	fake.err = errors.New("rate limited")
+
+	_, err = provider.BranchInfo(context.Background(), "grdl/git-get", "main")
+	require.Error(t, err)
+
+	fake.err = nil
+
+	_, err = provider.BranchInfo(context.Background(), "grdl/git-get", "main")
+	require.NoError(t, err)
+	assert.Equal(t, 5, fake.calls, "errors are not cached")
+}
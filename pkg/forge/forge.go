@@ -0,0 +1,255 @@
+// Package forge implements reading repositories, pull requests and CI checks from forge APIs like GitHub, GitLab or Gitea
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownForge = errors.New("unknown forge")
+	ErrAPI          = errors.New("forge API request failed")
+	errNotFound     = errors.New("not found")
+)
+
+// Names of the supported forges.
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+	Gitea  = "gitea"
+)
+
+// Allowed are the names of the supported forges.
+var Allowed = []string{Gitea, GitHub, GitLab}
+
+// Number of repos requested per page.
+const perPage = 100
+
+// Repo is a repository listed by a forge.
+type Repo struct {
+	Name     string // Path of the repo relative to its owner, eg "git-get" or "subgroup/project" for GitLab subgroups.
+	HTTPURL  string
+	SSHURL   string
+	Archived bool
+	Fork     bool
+}
+
+// States of pull requests.
+const (
+	StateOpen   = "open"
+	StateDraft  = "draft"
+	StateMerged = "merged"
+	StateClosed = "closed"
+)
+
+// Combined states of CI checks.
+const (
+	ChecksSuccess = "success"
+	ChecksPending = "pending"
+	ChecksFailure = "failure"
+)
+
+// PullRequest is the latest pull request (or merge request) opened from a branch.
+type PullRequest struct {
+	Number int    `json:"number"`
+	State  string `json:"state"`
+	URL    string `json:"url"`
+}
+
+// BranchInfo describes a branch on a forge.
+type BranchInfo struct {
+	PullRequest *PullRequest `json:"pullRequest,omitempty"` // Nil if there's no pull request from the branch.
+	Checks      string       `json:"checks,omitempty"`      // Combined state of CI checks of the latest commit. Empty if there are none.
+}
+
+// Provider lists repositories hosted on a forge and reads the state of their branches.
+type Provider interface {
+	// ListRepos returns all repos of an organization (or a group) or, if there's no such organization, of a user.
+	ListRepos(ctx context.Context, owner string) ([]Repo, error)
+	// BranchInfo returns the latest pull request and the CI checks state of a branch of a repo, eg "grdl/git-get".
+	// Branches which don't exist on the forge give an empty BranchInfo.
+	BranchInfo(ctx context.Context, repo string, branch string) (BranchInfo, error)
+}
+
+// Config configures the access to a forge API.
+type Config struct {
+	BaseURL string // Base URL of the API. The public instance of the forge is used if empty.
+	Token   string // Access token, needed for private repos and to avoid rate limits. Optional.
+	Client  *http.Client
+}
+
+// knownHosts maps the hosts of public forges to the forge names.
+var knownHosts = map[string]string{
+	"github.com":   GitHub,
+	"gitlab.com":   GitLab,
+	"gitea.com":    Gitea,
+	"codeberg.org": Gitea,
+}
+
+// ForHost returns the name of the forge on a known public host, eg "github" for "github.com", or an empty string for other hosts.
+func ForHost(host string) string {
+	return knownHosts[host]
+}
+
+// APIURL returns the base API URL of a forge on a given host, eg "https://gitlab.example.com/api/v4".
+func APIURL(name string, host string) string {
+	switch {
+	case name == GitHub && host == "github.com":
+		return gitHubURL
+	case name == GitHub:
+		return "https://" + host + "/api/v3"
+	case name == GitLab:
+		return "https://" + host + "/api/v4"
+	case name == Gitea:
+		return "https://" + host + "/api/v1"
+	default:
+		return ""
+	}
+}
+
+// New creates a Provider for a forge with a given name.
+func New(name string, conf Config) (Provider, error) {
+	if conf.Client == nil {
+		conf.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	switch name {
+	case GitHub:
+		return newGitHub(conf), nil
+	case GitLab:
+		return newGitLab(conf), nil
+	case Gitea:
+		return newGitea(conf), nil
+	default:
+		return nil, fmt.Errorf("%w %q, allowed values: [%s]", ErrUnknownForge, name, strings.Join(Allowed, ", "))
+	}
+}
+
+// client makes paginated GET requests to a forge API.
+type client struct {
+	baseURL   string
+	http      *http.Client
+	pageParam string // Name of the query parameter with the page size, eg "per_page".
+	auth      func(req *http.Request)
+}
+
+// get decodes the JSON response of a GET request into v. It returns errNotFound on 404 responses.
+func (c *client) get(ctx context.Context, path string, query url.Values, v any) error {
+	u := strings.TrimSuffix(c.baseURL, "/") + path + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if c.auth != nil {
+		c.auth(req)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrAPI, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("%w: GET %s: %s %s", ErrAPI, u, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: GET %s: invalid response: %w", ErrAPI, u, err)
+	}
+
+	return nil
+}
+
+// listAll fetches all pages of a list endpoint and converts the items into Repos.
+func listAll[T any](ctx context.Context, c *client, path string, query url.Values, convert func(T) Repo) ([]Repo, error) {
+	var repos []Repo
+
+	query.Set(c.pageParam, strconv.Itoa(perPage))
+
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var items []T
+		if err := c.get(ctx, path, query, &items); err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			repos = append(repos, convert(item))
+		}
+
+		if len(items) < perPage {
+			return repos, nil
+		}
+	}
+}
+
+// listOrgOrUser lists the repos from the organization path or, if the organization doesn't exist, from the user path.
+func listOrgOrUser[T any](ctx context.Context, c *client, orgPath string, userPath string, query url.Values, convert func(T) Repo) ([]Repo, error) {
+	repos, err := listAll(ctx, c, orgPath, query, convert)
+	if errors.Is(err, errNotFound) {
+		repos, err = listAll(ctx, c, userPath, query, convert)
+	}
+
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("%w: no organization nor user found at %s", ErrAPI, c.baseURL)
+	}
+
+	slices.SortFunc(repos, func(a, b Repo) int { return strings.Compare(a.Name, b.Name) })
+
+	return repos, err
+}
+
+// ignoreNotFound returns nil if err is errNotFound, so missing branches or repos give empty results instead of errors.
+func ignoreNotFound(err error) error {
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// escapeRef escapes each segment of a branch name, so branches like "feature/login" can be used in URL paths.
+func escapeRef(ref string) string {
+	segments := strings.Split(ref, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// combineChecks returns the more severe of two checks states: failure is worse than pending, which is worse than success.
+func combineChecks(a string, b string) string {
+	severity := []string{"", ChecksSuccess, ChecksPending, ChecksFailure}
+	if slices.Index(severity, b) > slices.Index(severity, a) {
+		return b
+	}
+
+	return a
+}
+
+// relativeName returns the path of a repo relative to its owner, eg "grdl/git-get" => "git-get".
+func relativeName(fullName string, owner string) string {
+	return strings.TrimPrefix(fullName, strings.Trim(owner, "/")+"/")
+}
@@ -0,0 +1,285 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI serves the items of list endpoints with pagination, using the given name of the page size parameter.
+// Paths which are not in the items map return 404. If a token is given, requests without the expected auth header return 401.
+func fakeAPI(t *testing.T, pageParam string, header string, value string, items map[string][]map[string]any) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header != "" && r.Header.Get(header) != value {
+			http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+
+			return
+		}
+
+		all, ok := items[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		size, _ := strconv.Atoi(r.URL.Query().Get(pageParam))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		start := min((page-1)*size, len(all))
+		end := min(start+size, len(all))
+
+		json.NewEncoder(w).Encode(all[start:end]) //nolint:errcheck
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGitHub(t *testing.T) {
+	t.Parallel()
+
+	var many []map[string]any
+	for i := range 150 {
+		many = append(many, map[string]any{"full_name": fmt.Sprintf("big/repo%03d", i)})
+	}
+
+	server := fakeAPI(t, "per_page", "Authorization", "Bearer secret", map[string][]map[string]any{
+		"/orgs/grdl/repos": {
+			{"full_name": "grdl/git-get", "clone_url": "https://github.com/grdl/git-get.git", "ssh_url": "git@github.com:grdl/git-get.git"},
+			{"full_name": "grdl/old", "archived": true},
+			{"full_name": "grdl/fork", "fork": true},
+		},
+		"/users/someone/repos": {
+			{"full_name": "someone/dotfiles"},
+		},
+		"/orgs/big/repos": many,
+	})
+
+	provider, err := New(GitHub, Config{BaseURL: server.URL, Token: "secret"})
+	require.NoError(t, err)
+
+	repos, err := provider.ListRepos(context.Background(), "grdl")
+	require.NoError(t, err)
+	assert.Equal(t, []Repo{
+		{Name: "fork", Fork: true},
+		{Name: "git-get", HTTPURL: "https://github.com/grdl/git-get.git", SSHURL: "git@github.com:grdl/git-get.git"},
+		{Name: "old", Archived: true},
+	}, repos)
+
+	repos, err = provider.ListRepos(context.Background(), "someone")
+	require.NoError(t, err)
+	assert.Equal(t, []Repo{{Name: "dotfiles"}}, repos)
+
+	repos, err = provider.ListRepos(context.Background(), "big")
+	require.NoError(t, err)
+	assert.Len(t, repos, 150)
+
+	_, err = provider.ListRepos(context.Background(), "nobody")
+	assert.ErrorIs(t, err, ErrAPI)
+
+	provider, err = New(GitHub, Config{BaseURL: server.URL, Token: "wrong"})
+	require.NoError(t, err)
+
+	_, err = provider.ListRepos(context.Background(), "grdl")
+	assert.ErrorIs(t, err, ErrAPI)
+}
+
+func TestGitLab(t *testing.T) {
+	t.Parallel()
+
+	server := fakeAPI(t, "per_page", "PRIVATE-TOKEN", "secret", map[string][]map[string]any{
+		"/groups/grdl%2Fsub/projects": {
+			{"path_with_namespace": "grdl/sub/project", "http_url_to_repo": "https://gitlab.com/grdl/sub/project.git"},
+			{"path_with_namespace": "grdl/sub/nested/fork", "forked_from_project": map[string]any{"id": 1}},
+		},
+		"/users/someone/projects": {
+			{"path_with_namespace": "someone/dotfiles", "archived": true},
+		},
+	})
+
+	provider, err := New(GitLab, Config{BaseURL: server.URL, Token: "secret"})
+	require.NoError(t, err)
+
+	repos, err := provider.ListRepos(context.Background(), "grdl/sub")
+	require.NoError(t, err)
+	assert.Equal(t, []Repo{
+		{Name: "nested/fork", Fork: true},
+		{Name: "project", HTTPURL: "https://gitlab.com/grdl/sub/project.git"},
+	}, repos)
+
+	repos, err = provider.ListRepos(context.Background(), "someone")
+	require.NoError(t, err)
+	assert.Equal(t, []Repo{{Name: "dotfiles", Archived: true}}, repos)
+}
+
+func TestGitea(t *testing.T) {
+	t.Parallel()
+
+	server := fakeAPI(t, "limit", "Authorization", "token secret", map[string][]map[string]any{
+		"/orgs/grdl/repos": {
+			{"full_name": "grdl/git-get", "clone_url": "https://gitea.com/grdl/git-get.git", "ssh_url": "git@gitea.com:grdl/git-get.git"},
+		},
+	})
+
+	provider, err := New(Gitea, Config{BaseURL: server.URL, Token: "secret"})
+	require.NoError(t, err)
+
+	repos, err := provider.ListRepos(context.Background(), "grdl")
+	require.NoError(t, err)
+	assert.Equal(t, []Repo{
+		{Name: "git-get", HTTPURL: "https://gitea.com/grdl/git-get.git", SSHURL: "git@gitea.com:grdl/git-get.git"},
+	}, repos)
+}
+
+func TestUnknownForge(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("bitbucket", Config{})
+	assert.ErrorIs(t, err, ErrUnknownForge)
+}
+
+// fakeResponses serves fixed JSON responses by path. Paths which are not in the map return 404.
+func fakeResponses(t *testing.T, responses map[string]any) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		json.NewEncoder(w).Encode(response) //nolint:errcheck
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGitHubBranchInfo(t *testing.T) {
+	t.Parallel()
+
+	server := fakeResponses(t, map[string]any{
+		"/repos/grdl/git-get/pulls": []map[string]any{
+			{"number": 12, "state": "open", "draft": true, "html_url": "https://github.com/grdl/git-get/pull/12"},
+		},
+		"/repos/grdl/git-get/commits/feature/login/status": map[string]any{"state": "success", "total_count": 1},
+		"/repos/grdl/git-get/commits/feature/login/check-runs": map[string]any{
+			"check_runs": []map[string]any{
+				{"status": "completed", "conclusion": "success"},
+				{"status": "in_progress"},
+			},
+		},
+		"/repos/grdl/other/pulls":                    []map[string]any{},
+		"/repos/grdl/other/commits/main/status":      map[string]any{"state": "pending", "total_count": 0},
+		"/repos/grdl/other/commits/main/check-runs":  map[string]any{"check_runs": []map[string]any{{"status": "completed", "conclusion": "timed_out"}}},
+		"/repos/grdl/merged/pulls":                   []map[string]any{{"number": 3, "state": "closed", "merged_at": "2024-01-01T00:00:00Z"}},
+		"/repos/grdl/merged/commits/done/status":     map[string]any{"state": "pending", "total_count": 0},
+		"/repos/grdl/merged/commits/done/check-runs": map[string]any{"check_runs": []map[string]any{}},
+	})
+
+	provider, err := New(GitHub, Config{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	info, err := provider.BranchInfo(context.Background(), "grdl/git-get", "feature/login")
+	require.NoError(t, err)
+	assert.Equal(t, BranchInfo{
+		PullRequest: &PullRequest{Number: 12, State: StateDraft, URL: "https://github.com/grdl/git-get/pull/12"},
+		Checks:      ChecksPending,
+	}, info)
+
+	info, err = provider.BranchInfo(context.Background(), "grdl/other", "main")
+	require.NoError(t, err)
+	assert.Equal(t, BranchInfo{Checks: ChecksFailure}, info)
+
+	info, err = provider.BranchInfo(context.Background(), "grdl/merged", "done")
+	require.NoError(t, err)
+	assert.Equal(t, BranchInfo{PullRequest: &PullRequest{Number: 3, State: StateMerged}}, info)
+
+	info, err = provider.BranchInfo(context.Background(), "grdl/missing", "main")
+	require.NoError(t, err)
+	assert.Equal(t, BranchInfo{}, info)
+}
+
+func TestGitLabBranchInfo(t *testing.T) {
+	t.Parallel()
+
+	server := fakeResponses(t, map[string]any{
+		"/projects/grdl%2Fsub%2Fproject/merge_requests": []map[string]any{{"iid": 7, "state": "opened", "web_url": "https://gitlab.com/mr/7"}},
+		"/projects/grdl%2Fsub%2Fproject/pipelines":      []map[string]any{{"status": "failed"}},
+	})
+
+	provider, err := New(GitLab, Config{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	info, err := provider.BranchInfo(context.Background(), "grdl/sub/project", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, BranchInfo{
+		PullRequest: &PullRequest{Number: 7, State: StateOpen, URL: "https://gitlab.com/mr/7"},
+		Checks:      ChecksFailure,
+	}, info)
+}
+
+func TestGiteaBranchInfo(t *testing.T) {
+	t.Parallel()
+
+	server := fakeResponses(t, map[string]any{
+		"/repos/grdl/git-get/pulls": []map[string]any{
+			{"number": 5, "state": "open", "head": map[string]any{"ref": "other"}},
+			{"number": 4, "state": "closed", "merged": true, "head": map[string]any{"ref": "feature"}},
+		},
+		"/repos/grdl/git-get/commits/feature/status": map[string]any{"state": "success", "total_count": 2},
+	})
+
+	provider, err := New(Gitea, Config{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	info, err := provider.BranchInfo(context.Background(), "grdl/git-get", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, BranchInfo{PullRequest: &PullRequest{Number: 4, State: StateMerged}, Checks: ChecksSuccess}, info)
+}
+
+func TestAPIURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "https://api.github.com", APIURL(GitHub, "github.com"))
+	assert.Equal(t, "https://github.example.com/api/v3", APIURL(GitHub, "github.example.com"))
+	assert.Equal(t, "https://gitlab.example.com/api/v4", APIURL(GitLab, "gitlab.example.com"))
+	assert.Equal(t, "https://codeberg.org/api/v1", APIURL(ForHost("codeberg.org"), "codeberg.org"))
+	assert.Empty(t, APIURL(ForHost("example.com"), "example.com"))
+}
+
+func TestWebURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		forge  string
+		target WebTarget
+		want   string
+	}{
+		{GitHub, WebTarget{}, "https://example.com/grdl/git-get"},
+		{GitHub, WebTarget{Branch: "feature/login"}, "https://example.com/grdl/git-get/tree/feature/login"},
+		{GitHub, WebTarget{Branch: "main", File: "/pkg/a b.go"}, "https://example.com/grdl/git-get/blob/main/pkg/a%20b.go"},
+		{GitLab, WebTarget{Branch: "main", Commit: "abc", File: "go.mod"}, "https://example.com/grdl/git-get/-/blob/abc/go.mod"},
+		{Gitea, WebTarget{Branch: "main"}, "https://example.com/grdl/git-get/src/branch/main"},
+		{Gitea, WebTarget{Commit: "abc", File: "go.mod"}, "https://example.com/grdl/git-get/src/commit/abc/go.mod"},
+		{"", WebTarget{Commit: "abc"}, "https://example.com/grdl/git-get/commit/abc"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, WebURL(test.forge, "https://example.com/grdl/git-get/", test.target))
+	}
+}
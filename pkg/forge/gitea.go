@@ -0,0 +1,122 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const giteaURL = "https://gitea.com/api/v1"
+
+// gitea lists repos using the Gitea (and Forgejo) REST API.
+type gitea struct {
+	client *client
+}
+
+type giteaRepo struct {
+	FullName string `json:"full_name"`
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+	Archived bool   `json:"archived"`
+	Fork     bool   `json:"fork"`
+}
+
+type giteaPull struct {
+	Number  int    `json:"number"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+type giteaStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+func newGitea(conf Config) *gitea {
+	c := &client{
+		baseURL:   conf.BaseURL,
+		http:      conf.Client,
+		pageParam: "limit",
+	}
+
+	if c.baseURL == "" {
+		c.baseURL = giteaURL
+	}
+
+	if conf.Token != "" {
+		c.auth = func(req *http.Request) {
+			req.Header.Set("Authorization", "token "+conf.Token)
+		}
+	}
+
+	return &gitea{client: c}
+}
+
+// ListRepos implements the Provider interface.
+func (g *gitea) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	name := url.PathEscape(owner)
+
+	return listOrgOrUser(ctx, g.client, "/orgs/"+name+"/repos", "/users/"+name+"/repos", url.Values{},
+		func(r giteaRepo) Repo {
+			return Repo{
+				Name:     relativeName(r.FullName, owner),
+				HTTPURL:  r.CloneURL,
+				SSHURL:   r.SSHURL,
+				Archived: r.Archived,
+				Fork:     r.Fork,
+			}
+		})
+}
+
+// BranchInfo implements the Provider interface.
+// Gitea can't filter pull requests by their head branch, so the latest updated ones are searched instead.
+func (g *gitea) BranchInfo(ctx context.Context, repo string, branch string) (BranchInfo, error) {
+	var info BranchInfo
+
+	var pulls []giteaPull
+
+	query := url.Values{"state": {"all"}, "sort": {"recentupdate"}, "limit": {"50"}}
+	if err := g.client.get(ctx, "/repos/"+repo+"/pulls", query, &pulls); err != nil {
+		return info, ignoreNotFound(err)
+	}
+
+	for _, pull := range pulls {
+		if pull.Head.Ref != branch {
+			continue
+		}
+
+		info.PullRequest = &PullRequest{Number: pull.Number, State: pull.State, URL: pull.HTMLURL}
+		if pull.Merged {
+			info.PullRequest.State = StateMerged
+		}
+
+		break
+	}
+
+	var status giteaStatus
+	if err := g.client.get(ctx, "/repos/"+repo+"/commits/"+escapeRef(branch)+"/status", url.Values{}, &status); err != nil {
+		return info, ignoreNotFound(err)
+	}
+
+	if status.TotalCount > 0 {
+		info.Checks = giteaChecks(status.State)
+	}
+
+	return info, nil
+}
+
+// giteaChecks converts the combined commit status into a checks state.
+func giteaChecks(state string) string {
+	switch state {
+	case "success", "warning":
+		return ChecksSuccess
+	case "pending":
+		return ChecksPending
+	default: // "failure", "error"
+		return ChecksFailure
+	}
+}
@@ -0,0 +1,144 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const gitHubURL = "https://api.github.com"
+
+// gitHub lists repos using the GitHub REST API. GitHub Enterprise works too with its "/api/v3" base URL.
+type gitHub struct {
+	client *client
+}
+
+type gitHubRepo struct {
+	FullName string `json:"full_name"`
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+	Archived bool   `json:"archived"`
+	Fork     bool   `json:"fork"`
+}
+
+type gitHubPull struct {
+	Number   int     `json:"number"`
+	State    string  `json:"state"`
+	Draft    bool    `json:"draft"`
+	MergedAt *string `json:"merged_at"`
+	HTMLURL  string  `json:"html_url"`
+}
+
+type gitHubStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+type gitHubCheckRuns struct {
+	CheckRuns []struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"check_runs"`
+}
+
+func newGitHub(conf Config) *gitHub {
+	c := &client{
+		baseURL:   conf.BaseURL,
+		http:      conf.Client,
+		pageParam: "per_page",
+	}
+
+	if c.baseURL == "" {
+		c.baseURL = gitHubURL
+	}
+
+	if conf.Token != "" {
+		c.auth = func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+conf.Token)
+		}
+	}
+
+	return &gitHub{client: c}
+}
+
+// ListRepos implements the Provider interface.
+func (g *gitHub) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	name := url.PathEscape(owner)
+
+	return listOrgOrUser(ctx, g.client, "/orgs/"+name+"/repos", "/users/"+name+"/repos", url.Values{"type": {"all"}},
+		func(r gitHubRepo) Repo {
+			return Repo{
+				Name:     relativeName(r.FullName, owner),
+				HTTPURL:  r.CloneURL,
+				SSHURL:   r.SSHURL,
+				Archived: r.Archived,
+				Fork:     r.Fork,
+			}
+		})
+}
+
+// BranchInfo implements the Provider interface.
+// Checks combine the legacy commit statuses and the check runs (used eg by GitHub Actions) of the latest commit of the branch.
+func (g *gitHub) BranchInfo(ctx context.Context, repo string, branch string) (BranchInfo, error) {
+	var info BranchInfo
+
+	owner, _, _ := strings.Cut(repo, "/")
+	query := url.Values{"head": {owner + ":" + branch}, "state": {"all"}, "sort": {"updated"}, "direction": {"desc"}, "per_page": {"1"}}
+
+	var pulls []gitHubPull
+	if err := g.client.get(ctx, "/repos/"+repo+"/pulls", query, &pulls); err != nil {
+		return info, ignoreNotFound(err)
+	}
+
+	if len(pulls) > 0 {
+		pull := pulls[0]
+		info.PullRequest = &PullRequest{Number: pull.Number, State: pull.State, URL: pull.HTMLURL}
+
+		switch {
+		case pull.MergedAt != nil:
+			info.PullRequest.State = StateMerged
+		case pull.Draft && pull.State == StateOpen:
+			info.PullRequest.State = StateDraft
+		}
+	}
+
+	ref := "/repos/" + repo + "/commits/" + escapeRef(branch)
+
+	var status gitHubStatus
+	if err := g.client.get(ctx, ref+"/status", url.Values{}, &status); err != nil {
+		return info, ignoreNotFound(err)
+	}
+
+	// The combined state is "pending" when there are no statuses at all.
+	if status.TotalCount > 0 {
+		info.Checks = gitHubChecks(status.State)
+	}
+
+	var runs gitHubCheckRuns
+	if err := g.client.get(ctx, ref+"/check-runs", url.Values{}, &runs); err != nil {
+		return info, ignoreNotFound(err)
+	}
+
+	for _, run := range runs.CheckRuns {
+		if run.Status != "completed" {
+			info.Checks = combineChecks(info.Checks, ChecksPending)
+		} else {
+			info.Checks = combineChecks(info.Checks, gitHubChecks(run.Conclusion))
+		}
+	}
+
+	return info, nil
+}
+
+// gitHubChecks converts the state of a commit status or the conclusion of a check run into a checks state.
+func gitHubChecks(state string) string {
+	switch state {
+	case "success", "neutral", "skipped":
+		return ChecksSuccess
+	case "pending":
+		return ChecksPending
+	default: // "failure", "error", "cancelled", "timed_out", "action_required"...
+		return ChecksFailure
+	}
+}
@@ -0,0 +1,122 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const gitLabURL = "https://gitlab.com/api/v4"
+
+// gitLab lists projects using the GitLab REST API. Projects of subgroups are included.
+type gitLab struct {
+	client *client
+}
+
+type gitLabProject struct {
+	PathWithNamespace string    `json:"path_with_namespace"`
+	HTTPURLToRepo     string    `json:"http_url_to_repo"`
+	SSHURLToRepo      string    `json:"ssh_url_to_repo"`
+	Archived          bool      `json:"archived"`
+	ForkedFrom        *struct{} `json:"forked_from_project"`
+}
+
+type gitLabMergeRequest struct {
+	IID    int    `json:"iid"`
+	State  string `json:"state"`
+	Draft  bool   `json:"draft"`
+	WebURL string `json:"web_url"`
+}
+
+type gitLabPipeline struct {
+	Status string `json:"status"`
+}
+
+func newGitLab(conf Config) *gitLab {
+	c := &client{
+		baseURL:   conf.BaseURL,
+		http:      conf.Client,
+		pageParam: "per_page",
+	}
+
+	if c.baseURL == "" {
+		c.baseURL = gitLabURL
+	}
+
+	if conf.Token != "" {
+		c.auth = func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", conf.Token)
+		}
+	}
+
+	return &gitLab{client: c}
+}
+
+// ListRepos implements the Provider interface. Owner can be a nested group, eg "gitlab-org/charts".
+func (g *gitLab) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	// Groups are identified by their URL-encoded full path, eg "gitlab-org%2Fcharts".
+	id := url.PathEscape(owner)
+
+	return listOrgOrUser(ctx, g.client, "/groups/"+id+"/projects", "/users/"+id+"/projects", url.Values{"include_subgroups": {"true"}},
+		func(p gitLabProject) Repo {
+			return Repo{
+				Name:     relativeName(p.PathWithNamespace, owner),
+				HTTPURL:  p.HTTPURLToRepo,
+				SSHURL:   p.SSHURLToRepo,
+				Archived: p.Archived,
+				Fork:     p.ForkedFrom != nil,
+			}
+		})
+}
+
+// BranchInfo implements the Provider interface. Checks are the status of the latest pipeline of the branch.
+func (g *gitLab) BranchInfo(ctx context.Context, repo string, branch string) (BranchInfo, error) {
+	var info BranchInfo
+
+	project := "/projects/" + url.PathEscape(repo)
+
+	var mrs []gitLabMergeRequest
+
+	query := url.Values{"source_branch": {branch}, "order_by": {"updated_at"}, "per_page": {"1"}}
+	if err := g.client.get(ctx, project+"/merge_requests", query, &mrs); err != nil {
+		return info, ignoreNotFound(err)
+	}
+
+	if len(mrs) > 0 {
+		mr := mrs[0]
+		info.PullRequest = &PullRequest{Number: mr.IID, State: mr.State, URL: mr.WebURL}
+
+		switch mr.State {
+		case "opened":
+			info.PullRequest.State = StateOpen
+			if mr.Draft {
+				info.PullRequest.State = StateDraft
+			}
+		case "locked":
+			info.PullRequest.State = StateClosed
+		}
+	}
+
+	var pipelines []gitLabPipeline
+	if err := g.client.get(ctx, project+"/pipelines", url.Values{"ref": {branch}, "per_page": {"1"}}, &pipelines); err != nil {
+		return info, ignoreNotFound(err)
+	}
+
+	if len(pipelines) > 0 {
+		info.Checks = gitLabChecks(pipelines[0].Status)
+	}
+
+	return info, nil
+}
+
+// gitLabChecks converts the status of a pipeline into a checks state.
+func gitLabChecks(status string) string {
+	switch status {
+	case "success", "skipped":
+		return ChecksSuccess
+	case "failed", "canceled":
+		return ChecksFailure
+	default: // "created", "pending", "running", "manual", "scheduled"...
+		return ChecksPending
+	}
+}
@@ -0,0 +1,83 @@
+package forge
+
+import (
+	"net/url"
+	"strings"
+)
+
+// webTemplates are templates of web URLs of a repo's branch, commit and file. "{base}" is replaced by the web URL of the repo,
+// "{branch}", "{commit}" and "{file}" by the branch name, commit hash and file path.
+type webTemplates struct {
+	branch     string
+	commit     string
+	branchFile string
+	commitFile string
+}
+
+var gitHubWeb = webTemplates{
+	branch:     "{base}/tree/{branch}",
+	commit:     "{base}/commit/{commit}",
+	branchFile: "{base}/blob/{branch}/{file}",
+	commitFile: "{base}/blob/{commit}/{file}",
+}
+
+var web = map[string]webTemplates{
+	GitHub: gitHubWeb,
+	GitLab: {
+		branch:     "{base}/-/tree/{branch}",
+		commit:     "{base}/-/commit/{commit}",
+		branchFile: "{base}/-/blob/{branch}/{file}",
+		commitFile: "{base}/-/blob/{commit}/{file}",
+	},
+	Gitea: {
+		branch:     "{base}/src/branch/{branch}",
+		commit:     "{base}/commit/{commit}",
+		branchFile: "{base}/src/branch/{branch}/{file}",
+		commitFile: "{base}/src/commit/{commit}/{file}",
+	},
+}
+
+// DefaultWebBase is the template of the web URL of a repo. "{host}" is replaced by the host of the remote URL (with a port only for http(s) remotes)
+// and "{path}" by the path of the repo, eg "grdl/git-get".
+const DefaultWebBase = "https://{host}/{path}"
+
+// WebTarget selects what a web URL of a repo points at. A file is shown at the commit, if it's set, or at the branch.
+// Empty fields are ignored, so an empty WebTarget points at the repo itself.
+type WebTarget struct {
+	Branch string
+	Commit string
+	File   string
+}
+
+// WebURL returns the web URL of a target in a repo of a forge with a given name, eg "https://github.com/grdl/git-get/tree/main".
+// Base is the web URL of the repo. Forges other than the supported ones are assumed to use the GitHub URL layout.
+func WebURL(name string, base string, target WebTarget) string {
+	base = strings.TrimSuffix(base, "/")
+
+	templates, ok := web[name]
+	if !ok {
+		templates = gitHubWeb
+	}
+
+	var template string
+
+	switch {
+	case target.File != "" && target.Commit != "":
+		template = templates.commitFile
+	case target.File != "" && target.Branch != "":
+		template = templates.branchFile
+	case target.Commit != "":
+		template = templates.commit
+	case target.Branch != "":
+		template = templates.branch
+	default:
+		return base
+	}
+
+	return strings.NewReplacer(
+		"{base}", base,
+		"{branch}", escapeRef(target.Branch),
+		"{commit}", url.PathEscape(target.Commit),
+		"{file}", escapeRef(strings.TrimPrefix(target.File, "/")),
+	).Replace(template)
+}
@@ -5,20 +5,32 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/grdl/git-get/pkg/cfg"
 	"github.com/grdl/git-get/pkg/git"
 )
 
-var ErrMissingRepoArg = errors.New("missing <REPO> argument or --dump flag")
+var (
+	ErrMissingRepoArg  = errors.New("missing <REPO> argument or --dump flag")
+	errTrackNoUpstream = errors.New("--track-upstream requires --upstream")
+	errPathOutsideRoot = errors.New("path outside of the repos root")
+)
+
+// Name of the remote added with the --upstream flag or the "upstream=<url>" dump file option.
+const upstreamRemote = "upstream"
 
 // GetCfg provides configuration for the Get command.
 type GetCfg struct {
-	Branch    string
-	DefHost   string
-	DefScheme string
-	Dump      string
-	Root      string
-	SkipHost  bool
-	URL       string
+	Branch        string
+	DefHost       string
+	DefScheme     string
+	Dump          string
+	Root          string
+	Routes        []cfg.Route // Roots other than Root for repos matching their patterns.
+	SkipHost      bool
+	Tags          []string // Clone only the repos from the dump file with any of these tags.
+	TrackUpstream bool
+	Upstream      string
+	URL           string
 }
 
 // Get executes the "git get" command.
@@ -46,31 +58,55 @@ func cloneSingleRepo(conf *GetCfg) error {
 
 	opts := &git.CloneOpts{
 		URL:    url,
-		Path:   filepath.Join(conf.Root, URLToPath(*url, conf.SkipHost)),
+		Path:   filepath.Join(routeRoot(conf.Root, conf.Routes, url), URLToPath(*url, conf.SkipHost)),
 		Branch: conf.Branch,
 	}
 
+	if conf.TrackUpstream && conf.Upstream == "" {
+		return errTrackNoUpstream
+	}
+
+	if conf.Upstream != "" {
+		opts.Remotes, err = resolveRemotes(conf, []git.Remote{{Name: upstreamRemote, URL: conf.Upstream}})
+		if err != nil {
+			return err
+		}
+	}
+
+	if conf.TrackUpstream {
+		opts.Track = upstreamRemote
+	}
+
 	_, err = git.Clone(opts)
 
 	return err
 }
 
+// resolveRemotes expands the URLs of remotes the same way as the URL of the cloned repo, so eg "grdl/git-get" can be used.
+func resolveRemotes(conf *GetCfg, remotes []git.Remote) ([]git.Remote, error) {
+	resolved := make([]git.Remote, len(remotes))
+
+	for i, remote := range remotes {
+		url, err := ParseURL(remote.URL, conf.DefHost, conf.DefScheme)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL of remote %s: %w", remote.Name, err)
+		}
+
+		resolved[i] = git.Remote{Name: remote.Name, URL: url.String()}
+	}
+
+	return resolved, nil
+}
+
 func cloneDumpFile(conf *GetCfg) error {
-	parsedLines, err := parseDumpFile(conf.Dump)
+	dumpOpts, err := loadDump(conf)
 	if err != nil {
 		return err
 	}
 
-	for _, line := range parsedLines {
-		url, err := ParseURL(line.rawurl, conf.DefHost, conf.DefScheme)
-		if err != nil {
-			return err
-		}
-
-		opts := &git.CloneOpts{
-			URL:    url,
-			Path:   filepath.Join(conf.Root, URLToPath(*url, conf.SkipHost)),
-			Branch: line.branch,
+	for _, opts := range dumpOpts {
+		if !hasAnyTag(opts.Tags, conf.Tags) {
+			continue
 		}
 
 		// If target path already exists, skip cloning this repo
@@ -88,3 +124,83 @@ func cloneDumpFile(conf *GetCfg) error {
 
 	return nil
 }
+
+// loadDump fetches (if needed) and parses the dump file and returns the options of cloning each repo listed in it.
+func loadDump(conf *GetCfg) ([]*git.CloneOpts, error) {
+	dump := conf.Dump
+
+	var check includeCheck
+
+	if isRemoteSource(dump) {
+		source, err := newDumpSource(conf)
+		if err != nil {
+			return nil, err
+		}
+
+		if dump, check, err = source.fetch(dump); err != nil {
+			return nil, err
+		}
+	}
+
+	parsedLines, err := parseDumpFile(dump, check)
+	if err != nil {
+		return nil, err
+	}
+
+	dumpOpts := make([]*git.CloneOpts, 0, len(parsedLines))
+
+	for _, line := range parsedLines {
+		opts, err := dumpCloneOpts(conf, line, isRemoteSource(conf.Dump))
+		if err != nil {
+			return nil, err
+		}
+
+		dumpOpts = append(dumpOpts, opts)
+	}
+
+	return dumpOpts, nil
+}
+
+// dumpCloneOpts returns the options of cloning a repo listed in a dump file.
+// Dump files fetched from remote sources aren't trusted, so they can only clone into paths inside the repos root.
+func dumpCloneOpts(conf *GetCfg, line parsedLine, fetched bool) (*git.CloneOpts, error) {
+	url, err := ParseURL(line.rawurl, conf.DefHost, conf.DefScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	remotes, err := resolveRemotes(conf, line.remotes)
+	if err != nil {
+		return nil, err
+	}
+
+	root := routeRoot(conf.Root, conf.Routes, url)
+
+	opts := &git.CloneOpts{
+		URL:     url,
+		Path:    filepath.Join(root, URLToPath(*url, conf.SkipHost)),
+		Branch:  line.branch,
+		Remotes: remotes,
+		Track:   line.track,
+		Depth:   line.depth,
+		Sparse:  line.sparse,
+		Tags:    line.tags,
+	}
+
+	// Relative path overrides are relative to the repos root. The repo is pinned there, so it's not reported as misplaced.
+	if line.path != "" {
+		opts.Pinned = true
+		opts.Path = line.path
+		if !filepath.IsAbs(opts.Path) {
+			opts.Path = filepath.Join(root, opts.Path)
+		}
+	}
+
+	if fetched {
+		if rel, err := filepath.Rel(root, opts.Path); err != nil || !filepath.IsLocal(rel) {
+			return nil, fmt.Errorf("%w: %s, dump files fetched from %s can only clone into the repos root", errPathOutsideRoot, opts.Path, conf.Dump)
+		}
+	}
+
+	return opts, nil
+}
@@ -2,19 +2,67 @@
 package git
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/grdl/git-get/pkg/run"
 )
 
-// ConfigGlobal represents a global gitconfig file.
-type ConfigGlobal struct{}
+// Config represents gitconfig merged from all its scopes: system, global and the local config of the repo in the current directory,
+// together with files included by them. All "gitget.*" keys are read with a single git call the first time they're needed.
+type Config struct {
+	path   string // Path of the repo whose local config is read, instead of the one in the current directory.
+	once   sync.Once
+	values map[string]string
+}
+
+// Get reads a value from gitconfig. Returns empty string when key is missing.
+func (c *Config) Get(key string) string {
+	return c.Values()[canonicalKey(key)]
+}
+
+// Values returns all "gitget.*" keys with their values. Keys defined multiple times (eg, in global and local config) have the last value.
+// Section and key names are lowercase, eg "gitget.skip-host", but subsections keep their case, eg "gitget.GitHub.com.token".
+func (c *Config) Values() map[string]string {
+	c.once.Do(c.load)
+
+	return c.values
+}
+
+func (c *Config) load() {
+	c.values = map[string]string{}
 
-// Get reads a value from global gitconfig file. Returns empty string when key is missing.
-func (c *ConfigGlobal) Get(key string) string {
-	out, err := run.Git("config", "--global", key).AndCaptureLine()
-	// In case of error return an empty string, the missing value will fall back to a default.
+	// Without a scope flag, like --global, git reads all scopes and follows include and includeIf directives.
+	// Entries are NUL-terminated and the key is separated from the value by a newline, so values spanning multiple lines are read correctly.
+	lines, err := run.Git("config", "--null", "--get-regexp", `^gitget\.`).OnRepo(c.path).AndCaptureLines()
+	// In case of error (git exits with 1 when there are no matching keys) all values fall back to defaults.
 	if err != nil {
-		return ""
+		return
+	}
+
+	for _, entry := range strings.Split(strings.Join(lines, "\n"), "\x00") {
+		if entry == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(entry, "\n")
+		// A key without "=" is a boolean set to true, eg "[gitget] skip-host".
+		if !found {
+			value = "true"
+		}
+
+		c.values[key] = value
+	}
+}
+
+// canonicalKey lowercases the section and key name of a gitconfig key, the same way git does, eg "gitGet.Skip-Host" becomes "gitget.skip-host".
+func canonicalKey(key string) string {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+
+	if first < 0 {
+		return strings.ToLower(key)
 	}
 
-	return out
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
 }
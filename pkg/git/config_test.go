@@ -1,31 +1,26 @@
 package git
 
 import (
+	"os"
 	"testing"
 
 	"github.com/grdl/git-get/pkg/git/test"
-	"github.com/grdl/git-get/pkg/run"
 )
 
-// cfgStub represents a gitconfig file but instead of using a global one, it creates a temporary git repo and uses its local gitconfig.
-type cfgStub struct {
-	*test.Repo
-}
-
-func (c *cfgStub) Get(key string) string {
-	out, err := run.Git("config", "--local", key).OnRepo(c.Path()).AndCaptureLine()
-	if err != nil {
-		return ""
-	}
+// TestMain isolates the tests from the global and system gitconfig of the machine they run on.
+// Config reads all scopes, so keys like "gitget.host" set by the developer would leak into the results.
+func TestMain(m *testing.M) {
+	os.Setenv("GIT_CONFIG_GLOBAL", os.DevNull) //nolint:errcheck,usetesting // t.Setenv can't be used with parallel tests.
+	os.Setenv("GIT_CONFIG_NOSYSTEM", "1")      //nolint:errcheck,usetesting // t.Setenv can't be used with parallel tests.
 
-	return out
+	os.Exit(m.Run())
 }
 
 func TestGitConfig(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		name        string
-		configMaker func(t *testing.T) *cfgStub
+		configMaker func(t *testing.T) *Config
 		key         string
 		want        string
 	}{
@@ -50,6 +45,31 @@ func TestGitConfig(t *testing.T) {
 			configMaker: makeConfigValid,
 			key:         "gitget.missingkey",
 			want:        "",
+		}, {
+			name:        "key name in different case",
+			configMaker: makeConfigValid,
+			key:         "gitGet.Host",
+			want:        "github.com",
+		}, {
+			name:        "last value wins",
+			configMaker: makeConfigIncluded,
+			key:         "gitget.root",
+			want:        "/local/root",
+		}, {
+			name:        "boolean without value",
+			configMaker: makeConfigIncluded,
+			key:         "gitget.skip-host",
+			want:        "true",
+		}, {
+			name:        "host key from included file",
+			configMaker: makeConfigIncluded,
+			key:         "gitget.GitLab.example.com.token",
+			want:        "secret",
+		}, {
+			name:        "multi-line value",
+			configMaker: makeConfigIncluded,
+			key:         "gitget.note",
+			want:        "first line\nsecond line",
 		},
 	}
 
@@ -67,18 +87,20 @@ func TestGitConfig(t *testing.T) {
 	}
 }
 
-func makeConfigEmpty(t *testing.T) *cfgStub {
+func makeConfigEmpty(t *testing.T) *Config {
 	t.Helper()
 
-	return &cfgStub{
-		Repo: test.RepoWithEmptyConfig(t),
-	}
+	return &Config{path: test.RepoWithEmptyConfig(t).Path()}
 }
 
-func makeConfigValid(t *testing.T) *cfgStub {
+func makeConfigValid(t *testing.T) *Config {
 	t.Helper()
 
-	return &cfgStub{
-		Repo: test.RepoWithValidConfig(t),
-	}
+	return &Config{path: test.RepoWithValidConfig(t).Path()}
+}
+
+func makeConfigIncluded(t *testing.T) *Config {
+	t.Helper()
+
+	return &Config{path: test.RepoWithIncludedConfig(t).Path()}
 }
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+var errCopyMismatch = errors.New("copy differs from the original")
+
+// rename moves src to dst. When they are on different filesystems, where os.Rename fails with EXDEV,
+// src is copied into dst, the copy is verified and only then src is removed.
+// If the copy fails, the partial copy is removed and src is left untouched.
+func rename(src string, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	return moveByCopy(src, dst)
+}
+
+// moveByCopy copies src into dst, which must not exist, verifies the copy and removes src.
+func moveByCopy(src string, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("failed copying to another filesystem: %s: %w", dst, fs.ErrExist)
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst) //nolint:errcheck // The copy error is more relevant.
+
+		return fmt.Errorf("failed copying to another filesystem: %w", err)
+	}
+
+	if err := compareTrees(src, dst); err != nil {
+		os.RemoveAll(dst) //nolint:errcheck // The verification error is more relevant.
+
+		return fmt.Errorf("failed verifying the copy on another filesystem: %w", err)
+	}
+
+	if err := os.RemoveAll(src); err != nil {
+		return fmt.Errorf("copied to %s but failed removing the original: %w", dst, err)
+	}
+
+	return nil
+}
+
+// copyTree copies the directory tree of src into dst, which must not exist. Permissions and symlinks are preserved.
+func copyTree(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			// Directories are created writable, so their files can be copied, and get their permissions afterwards.
+			if err := os.Mkdir(target, 0700); err != nil {
+				return err
+			}
+
+			return nil
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("can't copy %s: not a regular file, directory or symlink", path)
+		}
+	})
+}
+
+// copyFile copies the content of a regular file into a new file with given permissions.
+func copyFile(src string, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+
+		return err
+	}
+
+	return out.Close()
+}
+
+// compareTrees checks if dst has the same directories, files (with the same sizes) and symlinks as src.
+// Permissions of directories are restored along the way, copyTree creates them writable.
+func compareTrees(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		want, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		got, err := os.Lstat(filepath.Join(dst, rel))
+		if err != nil {
+			return err
+		}
+
+		if want.Mode().Type() != got.Mode().Type() || want.Mode().IsRegular() && want.Size() != got.Size() {
+			return fmt.Errorf("%w: %s", errCopyMismatch, rel)
+		}
+
+		if entry.IsDir() {
+			return os.Chmod(filepath.Join(dst, rel), want.Mode().Perm())
+		}
+
+		return nil
+	})
+}
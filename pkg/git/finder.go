@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Max number of concurrently running status loading workers.
@@ -102,32 +104,17 @@ func (f *RepoFinder) Find() error {
 	return nil
 }
 
+// Repos returns the repositories found by Find().
+func (f *RepoFinder) Repos() []*Repo {
+	return f.repos
+}
+
 // LoadAll loads and returns sorted slice of statuses of all repositories found by RepoFinder.
-// If fetch equals true, it first fetches from the remote repo before loading the status.
 // Each repo is loaded concurrently by a separate worker, with max 100 workers being active at the same time.
-func (f *RepoFinder) LoadAll(fetch bool) []*Status {
-	statuses := []*Status{}
-
-	reposChan := make(chan *Repo, f.maxWorkers)
-	statusChan := make(chan *Status, f.maxWorkers)
-
-	// Fire up workers. They listen on reposChan, load status and send the result to statusChan.
-	for range f.maxWorkers {
-		go statusWorker(fetch, reposChan, statusChan)
-	}
-
-	// Start loading the slice of repos found by finder into the reposChan.
-	// It runs in a goroutine so that as soon as repos appear on the channel they can be processed and sent to statusChan.
-	go loadRepos(f.repos, reposChan)
-
-	// Read statuses from the statusChan and add then to the result slice.
-	// Close the channel when all repos are loaded.
-	for status := range statusChan {
-		statuses = append(statuses, status)
-		if len(statuses) == len(f.repos) {
-			close(statusChan)
-		}
-	}
+func (f *RepoFinder) LoadAll(opts LoadOpts) []*Status {
+	statuses := loadConcurrently(f.repos, f.maxWorkers, func(repo *Repo) *Status {
+		return repo.LoadStatus(opts)
+	})
 
 	// Sort the status slice by path
 	sort.Slice(statuses, func(i, j int) bool {
@@ -137,18 +124,45 @@ func (f *RepoFinder) LoadAll(fetch bool) []*Status {
 	return statuses
 }
 
-func loadRepos(repos []*Repo, reposChan chan<- *Repo) {
-	for _, repo := range repos {
-		reposChan <- repo
-	}
+// LoadReports loads and returns sorted slice of health reports of all repositories found by RepoFinder.
+// Branches without any commits in the staleAfter period are reported as stale.
+func (f *RepoFinder) LoadReports(staleAfter time.Duration) []*Report {
+	reports := loadConcurrently(f.repos, f.maxWorkers, func(repo *Repo) *Report {
+		return repo.LoadReport(staleAfter)
+	})
+
+	sort.Slice(reports, func(i, j int) bool {
+		return strings.Compare(reports[i].path, reports[j].path) < 0
+	})
 
-	close(reposChan)
+	return reports
 }
 
-func statusWorker(fetch bool, reposChan <-chan *Repo, statusChan chan<- *Status) {
-	for repo := range reposChan {
-		statusChan <- repo.LoadStatus(fetch)
+// loadConcurrently calls load on each repo and returns the results in the same order as repos.
+// Each repo is loaded by a separate goroutine, with max workers goroutines being active at the same time.
+func loadConcurrently[T any](repos []*Repo, workers int, load func(*Repo) T) []T {
+	results := make([]T, len(repos))
+	sem := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+
+	for i, repo := range repos {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = load(repo)
+
+			<-sem
+		}()
 	}
+
+	wg.Wait()
+
+	return results
 }
 
 // addIfOk adds the found repo to the repos slice if it can be opened.
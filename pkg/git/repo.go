@@ -1,12 +1,15 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grdl/git-get/pkg/run"
 )
@@ -15,20 +18,43 @@ const (
 	dotgit    = ".git"
 	untracked = "??" // Untracked files are marked as "??" in git status output.
 	main      = "main"
+	origin    = "origin"
 	head      = "HEAD"
 )
 
+var errNoRemoteBranch = errors.New("remote doesn't have any branch to track")
+
 // Repo represents a git Repository cloned or initialized on disk.
 type Repo struct {
 	path string
 }
 
+// Commit contains basic information about a commit.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Date    time.Time `json:"date"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+}
+
+// Remote is a named remote repository.
+type Remote struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
 // CloneOpts specify detail about Repository to clone.
 type CloneOpts struct {
-	URL    *url.URL
-	Path   string // TODO: should Path be a part of clone opts?
-	Branch string
-	Quiet  bool
+	URL     *url.URL
+	Path    string // TODO: should Path be a part of clone opts?
+	Branch  string
+	Remotes []Remote // Additional remotes added and fetched after cloning.
+	Track   string   // Name of a remote which the checked out branch should track instead of origin. Ignored if empty.
+	Depth   int      // Number of commits to fetch in a shallow clone. Full history is cloned if it's zero.
+	Sparse  []string // Directories to checkout in a sparse checkout. The whole worktree is checked out if it's empty.
+	Tags    []string // Tags to assign to the cloned repo.
+	Pinned  bool     // Path is a custom one, not derived from the URL. The cloned repo is marked with SetPinned.
+	Quiet   bool
 }
 
 // Open checks if given path can be accessed and returns a Repo instance pointing to it.
@@ -44,11 +70,21 @@ func Open(path string) (*Repo, error) {
 
 // Clone clones Repository specified with CloneOpts.
 func Clone(opts *CloneOpts) (*Repo, error) {
-	runGit := run.Git("clone", opts.URL.String(), opts.Path)
+	args := []string{"clone"}
 	if opts.Branch != "" {
-		runGit = run.Git("clone", "--branch", opts.Branch, "--single-branch", opts.URL.String(), opts.Path)
+		args = append(args, "--branch", opts.Branch, "--single-branch")
+	}
+
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+
+	if len(opts.Sparse) > 0 {
+		args = append(args, "--sparse")
 	}
 
+	runGit := run.Git(append(args, opts.URL.String(), opts.Path)...)
+
 	var err error
 	if opts.Quiet {
 		err = runGit.AndShutUp()
@@ -63,8 +99,90 @@ func Clone(opts *CloneOpts) (*Repo, error) {
 	}
 
 	Repo, err := Open(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, remote := range opts.Remotes {
+		if err := Repo.AddRemote(remote, opts.Quiet); err != nil {
+			return Repo, err
+		}
+	}
 
-	return Repo, err
+	if opts.Track != "" {
+		if err := Repo.TrackRemote(opts.Track); err != nil {
+			return Repo, err
+		}
+	}
+
+	if len(opts.Sparse) > 0 {
+		if err := Repo.SetSparsePaths(opts.Sparse); err != nil {
+			return Repo, err
+		}
+	}
+
+	if len(opts.Tags) > 0 {
+		if err := Repo.SetTags(opts.Tags); err != nil {
+			return Repo, err
+		}
+	}
+
+	if opts.Pinned {
+		if err := Repo.SetPinned(); err != nil {
+			return Repo, err
+		}
+	}
+
+	return Repo, nil
+}
+
+// AddRemote adds a remote to the repository and fetches all its branches.
+// The remote HEAD is set too, so the remote's default branch is known.
+func (r *Repo) AddRemote(remote Remote, quiet bool) error {
+	if err := run.Git("remote", "add", remote.Name, remote.URL).OnRepo(r.path).AndShutUp(); err != nil {
+		return fmt.Errorf("failed adding remote %s: %w", remote.Name, err)
+	}
+
+	fetch := run.Git("fetch", remote.Name).OnRepo(r.path)
+
+	var err error
+	if quiet {
+		err = fetch.AndShutUp()
+	} else {
+		err = fetch.AndShow()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	//nolint:errcheck // An empty remote doesn't have a HEAD. RemoteDefaultBranch falls back to "main" or "master" anyway.
+	run.Git("remote", "set-head", remote.Name, "--auto").OnRepo(r.path).AndShutUp()
+
+	return nil
+}
+
+// TrackRemote sets the upstream of the current branch to a branch of a given remote.
+// It's the remote branch with the same name, if it exists, or the default branch of the remote.
+func (r *Repo) TrackRemote(remote string) error {
+	current, err := r.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	upstream := fmt.Sprintf("%s/%s", remote, current)
+	if err := run.Git("rev-parse", "--verify", "--quiet", "refs/remotes/"+upstream).OnRepo(r.path).AndShutUp(); err != nil {
+		upstream, err = r.RemoteDefaultBranch(remote)
+		if err != nil {
+			return err
+		}
+	}
+
+	if upstream == "" {
+		return fmt.Errorf("%w: %s", errNoRemoteBranch, remote)
+	}
+
+	return run.Git("branch", "--set-upstream-to="+upstream, current).OnRepo(r.path).AndShutUp()
 }
 
 // Fetch preforms a git fetch on all remotes.
@@ -74,6 +192,26 @@ func (r *Repo) Fetch() error {
 	return err
 }
 
+// ResetToUpstream fetches the upstream of the current branch and resets the branch and the worktree to it.
+// Shallow clones stay shallow. Local changes are lost, so it's meant only for repos which are never modified locally.
+func (r *Repo) ResetToUpstream() error {
+	args := []string{"fetch", origin}
+	if depth, err := r.Depth(); err == nil && depth > 0 {
+		args = append(args, "--depth", "1")
+	}
+
+	if err := run.Git(args...).OnRepo(r.path).AndShutUp(); err != nil {
+		return err
+	}
+
+	return run.Git("reset", "--hard", "@{upstream}").OnRepo(r.path).AndShutUp()
+}
+
+// FastForward merges the upstream of the current branch into it, only if it can be fast-forwarded.
+func (r *Repo) FastForward() error {
+	return run.Git("merge", "--ff-only", "@{upstream}").OnRepo(r.path).AndShutUp()
+}
+
 // Uncommitted returns the number of uncommitted files in the Repository.
 // Only tracked files are not counted.
 func (r *Repo) Uncommitted() (int, error) {
@@ -188,8 +326,284 @@ func (r *Repo) AheadBehind(branch string, upstream string) (int, int, error) {
 	return ahead, behind, nil
 }
 
+// DefaultBranch returns the name of the default branch of the Repository.
+// It's the branch pointed to by the remote HEAD (eg, "origin/main") if it's known.
+// Otherwise it falls back to a local "main" or "master" branch and finally to the currently checked out branch.
+func (r *Repo) DefaultBranch() (string, error) {
+	out, err := run.Git("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD").OnRepo(r.path).AndCaptureLine()
+	if err == nil && out != "" {
+		return out, nil
+	}
+
+	branches, err := r.Branches()
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range []string{main, "master"} {
+		if slices.Contains(branches, name) {
+			return name, nil
+		}
+	}
+
+	return r.CurrentBranch()
+}
+
+// MergedBranches returns a list of local branches fully merged into a given base branch.
+// The base branch itself and its local counterpart (eg, "main" for "origin/main") are not included.
+func (r *Repo) MergedBranches(base string) ([]string, error) {
+	out, err := run.Git("branch", "--format=%(refname:short)", "--merged", base).OnRepo(r.path).AndCaptureLines()
+	if err != nil {
+		return nil, err
+	}
+
+	local := strings.TrimPrefix(base, "origin/")
+
+	var merged []string
+
+	for _, branch := range out {
+		if branch == "" || branch == base || branch == local || strings.Contains(branch, "HEAD detached") {
+			continue
+		}
+
+		merged = append(merged, branch)
+	}
+
+	return merged, nil
+}
+
+// GoneBranches returns a list of local branches whose upstream branch has been deleted from the remote.
+func (r *Repo) GoneBranches() ([]string, error) {
+	out, err := run.Git("for-each-ref", "--format=%(refname:short)%09%(upstream:track)", "refs/heads").OnRepo(r.path).AndCaptureLines()
+	if err != nil {
+		return nil, err
+	}
+
+	var gone []string
+
+	for _, line := range out {
+		branch, track, _ := strings.Cut(line, "\t")
+		if track == "[gone]" {
+			gone = append(gone, branch)
+		}
+	}
+
+	return gone, nil
+}
+
+// LastCommitDates returns the date of the last commit on each local branch.
+func (r *Repo) LastCommitDates() (map[string]time.Time, error) {
+	out, err := run.Git("for-each-ref", "--format=%(refname:short)%09%(committerdate:unix)", "refs/heads").OnRepo(r.path).AndCaptureLines()
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]time.Time)
+
+	for _, line := range out {
+		branch, date, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+
+		unix, err := strconv.ParseInt(date, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		dates[branch] = time.Unix(unix, 0)
+	}
+
+	return dates, nil
+}
+
+// Stashes returns the number of stash entries in the Repository.
+func (r *Repo) Stashes() (int, error) {
+	out, err := run.Git("stash", "list").OnRepo(r.path).AndCaptureLines()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, line := range out {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Unpushed returns the number of commits on a given branch which don't exist on any remote.
+func (r *Repo) Unpushed(branch string) (int, error) {
+	out, err := run.Git("rev-list", "--count", branch, "--not", "--remotes").OnRepo(r.path).AndCaptureLine()
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(out)
+}
+
+// LastCommit returns the commit currently checked out (HEAD). It returns an empty Commit if the Repository has no commits yet.
+func (r *Repo) LastCommit() (Commit, error) {
+	if _, err := run.Git("rev-parse", "--quiet", "--verify", head).OnRepo(r.path).AndCaptureLine(); err != nil {
+		//nolint:nilerr // HEAD can't be verified only when there are no commits yet.
+		return Commit{}, nil
+	}
+
+	out, err := run.Git("log", "-1", "--format=%H%x00%ct%x00%an%x00%s", head).OnRepo(r.path).AndCaptureLine()
+	if err != nil {
+		return Commit{}, err
+	}
+
+	parts := strings.SplitN(out, "\x00", 4)
+	if len(parts) != 4 {
+		return Commit{}, fmt.Errorf("unexpected git log output: %q", out) //nolint:err113 // It's a bug if this ever happens.
+	}
+
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Commit{}, err
+	}
+
+	return Commit{
+		Hash:    parts[0],
+		Date:    time.Unix(unix, 0),
+		Author:  parts[2],
+		Subject: parts[3],
+	}, nil
+}
+
+// ResolveCommit returns the full hash of the commit a revision (eg, "HEAD~2", a tag or an abbreviated hash) points at.
+func (r *Repo) ResolveCommit(rev string) (string, error) {
+	return run.Git("rev-parse", "--verify", "--end-of-options", rev+"^{commit}").OnRepo(r.path).AndCaptureLine()
+}
+
+// LastWorkTreeChange returns the latest modification time of uncommitted and untracked files in the Repository.
+// It returns zero time if the worktree is clean.
+func (r *Repo) LastWorkTreeChange() (time.Time, error) {
+	out, err := run.Git("status", "--ignore-submodules", "--untracked-files=all", "--porcelain", "-z").OnRepo(r.path).AndCaptureLine()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+
+		// Renamed and copied entries are followed by an extra entry with the original path.
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+
+		info, err := os.Stat(filepath.Join(r.path, entry[3:]))
+		if err != nil {
+			continue // Deleted files don't have a modification time.
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// DeleteBranch force deletes a local branch.
+func (r *Repo) DeleteBranch(branch string) error {
+	return run.Git("branch", "-D", branch).OnRepo(r.path).AndShutUp()
+}
+
+// Operation returns a description of an operation in progress (eg, "REBASING 3/7", "MERGING") or an empty string if there's none.
+// It's detected the same way git itself does it for "git status" - by checking the state files inside the .git directory.
+func (r *Repo) Operation() (string, error) {
+	gitDir, err := r.gitDir()
+	if err != nil {
+		return "", err
+	}
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+
+		return err == nil
+	}
+
+	switch {
+	case exists("rebase-merge"):
+		return "REBASING" + progress(gitDir, "rebase-merge", "msgnum", "end"), nil
+	case exists(filepath.Join("rebase-apply", "applying")):
+		return "AM" + progress(gitDir, "rebase-apply", "next", "last"), nil
+	case exists("rebase-apply"):
+		return "REBASING" + progress(gitDir, "rebase-apply", "next", "last"), nil
+	case exists("MERGE_HEAD"):
+		return "MERGING", nil
+	case exists("CHERRY_PICK_HEAD"):
+		return "CHERRY-PICKING", nil
+	case exists("REVERT_HEAD"):
+		return "REVERTING", nil
+	case exists("BISECT_LOG"):
+		return "BISECTING", nil
+	default:
+		return "", nil
+	}
+}
+
+// progress reads the current step and the total number of steps of a rebase or am from the state files inside dir.
+// Returns a string like " 3/7" or an empty string if the state files can't be read.
+func progress(gitDir string, dir string, current string, total string) string {
+	cur, err := os.ReadFile(filepath.Join(gitDir, dir, current))
+	if err != nil {
+		return ""
+	}
+
+	tot, err := os.ReadFile(filepath.Join(gitDir, dir, total))
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" %s/%s", strings.TrimSpace(string(cur)), strings.TrimSpace(string(tot)))
+}
+
+// gitDir returns path to the .git directory of the Repository.
+// In linked worktrees and submodules .git is a file pointing to the actual git directory.
+func (r *Repo) gitDir() (string, error) {
+	path := filepath.Join(r.path, dotgit)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return path, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(string(content), "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(r.path, gitDir)
+	}
+
+	return gitDir, nil
+}
+
 // Remote returns URL of remote Repository.
+// It's the URL of "origin", even if the current branch tracks another remote (eg, "upstream" of a fork).
 func (r *Repo) Remote() (string, error) {
+	if url, err := run.Git("remote", "get-url", origin).OnRepo(r.path).AndCaptureLine(); err == nil {
+		return url, nil
+	}
+
 	// https://stackoverflow.com/a/16880000/1085632
 	out, err := run.Git("ls-remote", "--get-url").OnRepo(r.path).AndCaptureLine()
 	if err != nil {
@@ -201,15 +615,129 @@ func (r *Repo) Remote() (string, error) {
 		return "", err
 	}
 
-	// TODO: needs testing. What happens when there are more than 1 remotes?
+	// Without "origin", ls-remote uses the remote of the current branch's upstream. Use Remotes() to get all of them.
 	return out, nil
 }
 
+// Remotes returns all remotes of the Repository. The "origin" remote goes first, the rest is sorted by name.
+func (r *Repo) Remotes() ([]Remote, error) {
+	out, err := run.Git("remote", "-v").OnRepo(r.path).AndCaptureLines()
+	if err != nil {
+		return nil, err
+	}
+
+	var remotes []Remote
+
+	for _, line := range out {
+		// Each remote is listed twice: "origin\thttps://github.com/grdl/git-get (fetch)" and the same with "(push)".
+		name, rest, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasSuffix(rest, " (fetch)") {
+			continue
+		}
+
+		remotes = append(remotes, Remote{Name: name, URL: strings.TrimSuffix(rest, " (fetch)")})
+	}
+
+	slices.SortFunc(remotes, func(a, b Remote) int {
+		switch {
+		case a.Name == origin:
+			return -1
+		case b.Name == origin:
+			return 1
+		default:
+			return strings.Compare(a.Name, b.Name)
+		}
+	})
+
+	return remotes, nil
+}
+
+// RemoteDefaultBranch returns the default branch of a given remote (eg, "upstream/main").
+// It's the branch pointed to by the remote HEAD or, if it's not known, the remote's "main" or "master" branch.
+// Returns an empty string if none of them exist.
+func (r *Repo) RemoteDefaultBranch(remote string) (string, error) {
+	out, err := run.Git("symbolic-ref", "--quiet", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remote)).OnRepo(r.path).AndCaptureLine()
+	if err == nil && out != "" {
+		return out, nil
+	}
+
+	for _, name := range []string{main, "master"} {
+		ref := fmt.Sprintf("%s/%s", remote, name)
+		if err := run.Git("rev-parse", "--verify", "--quiet", "refs/remotes/"+ref).OnRepo(r.path).AndShutUp(); err == nil {
+			return ref, nil
+		}
+	}
+
+	return "", nil
+}
+
 // Path returns path to the Repository.
 func (r *Repo) Path() string {
 	return r.path
 }
 
+// Bundle writes all refs of the repository, including stashes, into a bundle file.
+func (r *Repo) Bundle(file string) error {
+	return run.Git("bundle", "create", file, "--all").OnRepo(r.path).AndShutUp()
+}
+
+// Remove deletes the repository from disk. Its parent directories left empty are deleted too,
+// but only the ones inside the stop directory. The stop directory itself is never deleted.
+func (r *Repo) Remove(stop string) error {
+	if err := os.RemoveAll(r.path); err != nil {
+		return fmt.Errorf("failed removing %s: %w", r.path, err)
+	}
+
+	removeEmptyParents(r.path, stop)
+
+	return nil
+}
+
+// Move moves the repository to a new path, creating its missing parent directories.
+// When the new path is on a different filesystem, the repository is copied there, verified and removed from the old path.
+// The old parent directories left empty are deleted, but only the ones inside the stop directory (or all of them if stop is empty).
+// The path of the repo is updated, so it can still be used after the move.
+func (r *Repo) Move(path string, stop string) error {
+	created := firstMissingDir(filepath.Dir(path))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed creating directory for %s: %w", path, err)
+	}
+
+	if err := rename(r.path, path); err != nil {
+		if created != "" {
+			removeEmptyParents(path, filepath.Dir(created))
+		}
+
+		return fmt.Errorf("failed moving %s to %s: %w", r.path, path, err)
+	}
+
+	removeEmptyParents(r.path, stop)
+	r.path = path
+
+	return nil
+}
+
+// firstMissingDir returns the topmost directory of the path which doesn't exist yet or an empty string if the whole path exists.
+func firstMissingDir(path string) string {
+	var missing string
+
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return missing
+		}
+
+		missing = path
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return missing
+		}
+
+		path = parent
+	}
+}
+
 // cleanupFailedClone removes empty directories created by a failed git clone.
 // Git itself will delete the final repo directory if a clone has failed,
 // but it won't delete all the parent dirs that it created when cloning.
@@ -220,8 +748,22 @@ func (r *Repo) Path() string {
 // os.Remove will only delete an empty dir so we traverse the path "upwards" and delete all directories
 // until a non-empty one is reached.
 func cleanupFailedClone(path string) {
+	removeEmptyParents(path, "")
+}
+
+// removeEmptyParents traverses the path "upwards" and deletes all directories until a non-empty one is reached.
+// If stop is not empty, it also stops when it reaches a directory outside of stop (or stop itself).
+func removeEmptyParents(path string, stop string) {
 	for {
 		path = filepath.Dir(path)
+
+		if stop != "" {
+			rel, err := filepath.Rel(stop, path)
+			if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return
+			}
+		}
+
 		if err := os.Remove(path); err != nil {
 			return
 		}
@@ -1,15 +1,22 @@
 package git
 
 import (
+	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/grdl/git-get/pkg/run"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUncommitted(t *testing.T) {
@@ -450,3 +457,666 @@ func createTestDirTree(t *testing.T) string {
 
 	return root
 }
+
+func TestMergedBranches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		base      string
+		want      []string
+	}{
+		{
+			name:      "only main branch",
+			repoMaker: test.RepoWithCommit,
+			base:      "main",
+			want:      nil,
+		},
+		{
+			name:      "merged and unmerged branches",
+			repoMaker: test.RepoWithMergedBranch,
+			base:      "main",
+			want:      []string{"feature/merged"},
+		},
+		{
+			name:      "remote base branch",
+			repoMaker: test.RepoWithBranchAhead,
+			base:      "origin/main",
+			want:      nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.MergedBranches(test.base)
+			if err != nil {
+				t.Errorf("got error %q", err)
+			}
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestGoneBranches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		want      []string
+	}{
+		{
+			name:      "empty",
+			repoMaker: test.RepoEmpty,
+			want:      nil,
+		},
+		{
+			name:      "branch with upstream",
+			repoMaker: test.RepoWithBranchWithUpstream,
+			want:      nil,
+		},
+		{
+			name:      "branch with gone upstream",
+			repoMaker: test.RepoWithGoneUpstream,
+			want:      []string{"feature/branch"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.GoneBranches()
+			if err != nil {
+				t.Errorf("got error %q", err)
+			}
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestStashes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		want      int
+	}{
+		{
+			name:      "empty",
+			repoMaker: test.RepoEmpty,
+			want:      0,
+		},
+		{
+			name:      "committed",
+			repoMaker: test.RepoWithCommit,
+			want:      0,
+		},
+		{
+			name:      "two stashes",
+			repoMaker: test.RepoWithStash,
+			want:      2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.Stashes()
+			if err != nil {
+				t.Errorf("got error %q", err)
+			}
+
+			if got != test.want {
+				t.Errorf("expected %d; got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestUnpushed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		branch    string
+		want      int
+	}{
+		{
+			name:      "fresh clone",
+			repoMaker: test.RepoWithBranchWithUpstream,
+			branch:    "feature/branch",
+			want:      0,
+		},
+		{
+			name:      "branch ahead",
+			repoMaker: test.RepoWithBranchAhead,
+			branch:    "feature/branch",
+			want:      1,
+		},
+		{
+			name:      "no remotes",
+			repoMaker: test.RepoWithCommit,
+			branch:    "main",
+			want:      1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.Unpushed(test.branch)
+			if err != nil {
+				t.Errorf("got error %q", err)
+			}
+
+			if got != test.want {
+				t.Errorf("expected %d; got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadReport(t *testing.T) {
+	t.Parallel()
+
+	r, _ := Open(test.RepoWithGoneUpstream(t).Path())
+
+	report := r.LoadReport(24 * time.Hour)
+
+	assert.Empty(t, report.Errors())
+	assert.Equal(t, []string{"feature/branch"}, report.Gone())
+	assert.Equal(t, []string{"feature/branch"}, report.Merged())
+	assert.Empty(t, report.Stale())
+	assert.Empty(t, report.Unpushed())
+
+	report = r.LoadReport(0)
+	assert.Equal(t, []string{"feature/branch", "main"}, report.Stale())
+}
+
+func TestOperation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		want      string
+	}{
+		{
+			name:      "empty",
+			repoMaker: test.RepoEmpty,
+			want:      "",
+		},
+		{
+			name:      "committed",
+			repoMaker: test.RepoWithCommit,
+			want:      "",
+		},
+		{
+			name:      "merging",
+			repoMaker: test.RepoMerging,
+			want:      "MERGING",
+		},
+		{
+			name:      "rebasing",
+			repoMaker: test.RepoRebasing,
+			want:      "REBASING 1/1",
+		},
+		{
+			name:      "cherry-picking",
+			repoMaker: test.RepoCherryPicking,
+			want:      "CHERRY-PICKING",
+		},
+		{
+			name:      "bisecting",
+			repoMaker: test.RepoBisecting,
+			want:      "BISECTING",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.Operation()
+			if err != nil {
+				t.Errorf("got error %q", err)
+			}
+
+			if got != test.want {
+				t.Errorf("expected %q; got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLastCommit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		repoMaker  func(*testing.T) *test.Repo
+		wantAuthor string
+		wantEmpty  bool
+	}{
+		{
+			name:      "empty",
+			repoMaker: test.RepoEmpty,
+			wantEmpty: true,
+		},
+		{
+			name:       "committed",
+			repoMaker:  test.RepoWithCommit,
+			wantAuthor: "Test User",
+		},
+		{
+			name:       "detached head",
+			repoMaker:  test.RepoWithTag,
+			wantAuthor: "Test User",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.LastCommit()
+			if err != nil {
+				t.Errorf("got error %q", err)
+			}
+
+			if test.wantEmpty {
+				assert.Equal(t, Commit{}, got)
+
+				return
+			}
+
+			assert.Equal(t, test.wantAuthor, got.Author)
+			assert.Contains(t, got.Subject, "Initial commit")
+			assert.Len(t, got.Hash, 40)
+			assert.WithinDuration(t, time.Now(), got.Date, time.Minute)
+		})
+	}
+}
+
+func TestLoadStatusLastCommit(t *testing.T) {
+	t.Parallel()
+
+	r, _ := Open(test.RepoWithUncommittedAndUntracked(t).Path())
+
+	status := r.LoadStatus(LoadOpts{})
+	assert.Empty(t, status.Errors())
+	assert.True(t, status.LastCommitDate().IsZero(), "last commit is loaded only on demand")
+	assert.True(t, status.LastActivity().IsZero(), "last activity is loaded only on demand")
+
+	status = r.LoadStatus(LoadOpts{LastCommit: true})
+	assert.Empty(t, status.Errors())
+	assert.Equal(t, "Test User", status.LastCommitAuthor())
+	assert.False(t, status.LastActivity().Before(status.LastCommitDate()))
+}
+
+func TestLastWorkTreeChange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		wantZero  bool
+	}{
+		{
+			name:      "empty",
+			repoMaker: test.RepoEmpty,
+			wantZero:  true,
+		},
+		{
+			name:      "committed",
+			repoMaker: test.RepoWithCommit,
+			wantZero:  true,
+		},
+		{
+			name:      "untracked and uncommitted",
+			repoMaker: test.RepoWithUncommittedAndUntracked,
+			wantZero:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.LastWorkTreeChange()
+			if err != nil {
+				t.Errorf("got error %q", err)
+			}
+
+			if test.wantZero {
+				assert.True(t, got.IsZero(), "expected zero time; got %s", got)
+			} else {
+				assert.WithinDuration(t, time.Now(), got, time.Minute)
+			}
+		})
+	}
+}
+
+func TestLoadStatusCounts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		repoMaker   func(*testing.T) *test.Repo
+		wantAhead   int
+		wantBehind  int
+		wantChanges int
+	}{
+		{
+			name:      "clean",
+			repoMaker: test.RepoWithCommit,
+		},
+		{
+			name:        "untracked and uncommitted",
+			repoMaker:   test.RepoWithUncommittedAndUntracked,
+			wantChanges: 2,
+		},
+		{
+			name:       "ahead and behind",
+			repoMaker:  test.RepoWithBranchAheadAndBehind,
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			status := r.LoadStatus(LoadOpts{})
+
+			assert.Empty(t, status.Errors())
+			assert.Equal(t, test.wantAhead, status.Ahead())
+			assert.Equal(t, test.wantBehind, status.Behind())
+			assert.Equal(t, test.wantChanges, status.Changes())
+		})
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	t.Parallel()
+
+	r, _ := Open(test.RepoWithCommit(t).Path())
+
+	before, err := r.DiskUsage()
+	assert.NoError(t, err)
+	assert.Positive(t, before.WorkTree)
+	assert.Positive(t, before.Git)
+	assert.Zero(t, before.LFS)
+
+	lfsDir := filepath.Join(r.path, dotgit, "lfs", "objects", "ab", "cd")
+	assert.NoError(t, os.MkdirAll(lfsDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(lfsDir, "abcd1234"), make([]byte, 500), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(r.path, "big.bin"), make([]byte, 1000), 0644))
+
+	after, err := r.DiskUsage()
+	assert.NoError(t, err)
+	assert.Equal(t, before.WorkTree+1000, after.WorkTree)
+	assert.Equal(t, before.Git, after.Git)
+	assert.Equal(t, int64(500), after.LFS)
+	assert.Equal(t, after.WorkTree+after.Git+after.LFS, after.Total())
+}
+
+func TestMove(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	src := filepath.Join(root, "src", "nested", "repo")
+	dst := filepath.Join(root, "dst", "nested", "repo")
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(src), 0755))
+	assert.NoError(t, os.Rename(test.RepoWithCommit(t).Path(), src))
+
+	r, _ := Open(src)
+	assert.NoError(t, r.Move(dst, root))
+
+	assert.Equal(t, dst, r.Path())
+	assert.DirExists(t, filepath.Join(dst, dotgit))
+	assert.NoDirExists(t, filepath.Join(root, "src"))
+	assert.DirExists(t, root)
+
+	// A failed move cleans up the directories it created.
+	assert.Error(t, r.Move(filepath.Join(dst, "inside", "itself"), root))
+	assert.Equal(t, dst, r.Path())
+	assert.NoDirExists(t, filepath.Join(dst, "inside"))
+}
+
+func TestMoveByCopy(t *testing.T) {
+	t.Parallel()
+
+	repo := test.RepoWithUncommittedAndUntracked(t)
+	src := repo.Path()
+	dst := filepath.Join(test.TempDir(t, ""), "repo")
+
+	require.NoError(t, os.Symlink("README.md", filepath.Join(src, "link")))
+	require.NoError(t, os.Chmod(filepath.Join(src, "untracked.txt"), 0600))
+
+	status := func(path string) string {
+		out, err := run.Git("status", "--porcelain").OnRepo(path).AndCaptureLines()
+		require.NoError(t, err)
+
+		return strings.Join(out, "\n")
+	}
+
+	want := status(src)
+
+	// os.Rename doesn't fail between directories on the same filesystem, so the fallback is called directly.
+	require.NoError(t, moveByCopy(src, dst))
+
+	assert.NoDirExists(t, src)
+	assert.Equal(t, want, status(dst))
+
+	link, err := os.Readlink(filepath.Join(dst, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "README.md", link)
+
+	info, err := os.Stat(filepath.Join(dst, "untracked.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Existing targets are never overwritten.
+	other := test.RepoWithCommit(t).Path()
+	require.ErrorIs(t, moveByCopy(other, dst), fs.ErrExist)
+	assert.DirExists(t, filepath.Join(other, dotgit))
+	assert.DirExists(t, filepath.Join(dst, dotgit))
+
+	// A failed copy leaves the source untouched and removes the partial copy.
+	require.NoError(t, syscall.Mkfifo(filepath.Join(other, "fifo"), 0600))
+
+	partial := filepath.Join(test.TempDir(t, ""), "repo")
+	require.Error(t, moveByCopy(other, partial))
+	assert.DirExists(t, filepath.Join(other, dotgit))
+	assert.NoDirExists(t, partial)
+}
+
+func TestRemotes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		want      []string
+	}{
+		{
+			name:      "no remotes",
+			repoMaker: test.RepoWithCommit,
+			want:      nil,
+		},
+		{
+			name:      "origin",
+			repoMaker: test.RepoWithBranchWithUpstream,
+			want:      []string{"origin"},
+		},
+		{
+			name:      "origin and upstream",
+			repoMaker: test.RepoWithUpstreamRemote,
+			want:      []string{"origin", "upstream"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, _ := Open(test.repoMaker(t).Path())
+
+			got, err := r.Remotes()
+			assert.NoError(t, err)
+
+			var names []string
+			for _, remote := range got {
+				names = append(names, remote.Name)
+				assert.NotEmpty(t, remote.URL)
+			}
+
+			assert.Equal(t, test.want, names)
+		})
+	}
+}
+
+func TestLoadStatusRemotes(t *testing.T) {
+	t.Parallel()
+
+	r, _ := Open(test.RepoWithUpstreamRemote(t).Path())
+
+	status := r.LoadStatus(LoadOpts{})
+
+	assert.Empty(t, status.Errors())
+	assert.Equal(t, []string{"origin", "upstream"}, status.Remotes())
+	assert.Equal(t, "origin/main", status.RemoteBranch("origin"))
+	assert.Empty(t, status.RemoteStatus("origin"))
+	assert.Equal(t, "upstream/main", status.RemoteBranch("upstream"))
+	assert.Equal(t, "1 behind", status.RemoteStatus("upstream"))
+	assert.NotEmpty(t, status.RemoteURL("upstream"))
+
+	// A single remote isn't compared, the branches status is enough.
+	r, _ = Open(test.RepoWithBranchWithUpstream(t).Path())
+
+	status = r.LoadStatus(LoadOpts{})
+	assert.Empty(t, status.Errors())
+	assert.Equal(t, []string{"origin"}, status.Remotes())
+	assert.Empty(t, status.RemoteBranch("origin"))
+	assert.Empty(t, status.RemoteStatus("origin"))
+}
+
+func TestCloneWithRemotes(t *testing.T) {
+	t.Parallel()
+
+	origin := test.RepoWithCommit(t)
+	upstream := test.RepoWithCommit(t)
+
+	r, err := Clone(&CloneOpts{
+		URL:     &url.URL{Scheme: "file", Path: origin.Path()},
+		Path:    filepath.Join(test.TempDir(t, ""), "clone"),
+		Remotes: []Remote{{Name: "upstream", URL: upstream.Path()}},
+		Quiet:   true,
+	})
+	assert.NoError(t, err)
+
+	remotes, err := r.Remotes()
+	assert.NoError(t, err)
+	assert.Equal(t, []Remote{{Name: "origin", URL: "file://" + origin.Path()}, {Name: "upstream", URL: upstream.Path()}}, remotes)
+
+	branch, err := r.RemoteDefaultBranch("upstream")
+	assert.NoError(t, err)
+	assert.Equal(t, "upstream/main", branch)
+}
+
+func TestCloneTrackingRemote(t *testing.T) {
+	t.Parallel()
+
+	origin := test.RepoWithCommit(t)
+	upstream := test.RepoWithCommit(t)
+
+	r, err := Clone(&CloneOpts{
+		URL:     &url.URL{Scheme: "file", Path: origin.Path()},
+		Path:    filepath.Join(test.TempDir(t, ""), "clone"),
+		Remotes: []Remote{{Name: "upstream", URL: upstream.Path()}},
+		Track:   "upstream",
+		Quiet:   true,
+	})
+	assert.NoError(t, err)
+
+	got, err := r.Upstream("main")
+	assert.NoError(t, err)
+	assert.Equal(t, "upstream/main", got)
+
+	err = r.TrackRemote("missing")
+	assert.Error(t, err)
+}
+
+func TestCloneWithSetup(t *testing.T) {
+	t.Parallel()
+
+	origin := test.RepoWithDirs(t)
+
+	r, err := Clone(&CloneOpts{
+		URL:    &url.URL{Scheme: "file", Path: origin.Path()},
+		Path:   filepath.Join(test.TempDir(t, ""), "clone"),
+		Depth:  1,
+		Sparse: []string{"docs"},
+		Tags:   []string{"work", "go"},
+		Pinned: true,
+		Quiet:  true,
+	})
+	assert.NoError(t, err)
+
+	status := r.LoadStatus(LoadOpts{})
+	assert.Empty(t, status.Errors())
+	assert.Zero(t, status.Depth(), "setup is loaded only on demand")
+	assert.Nil(t, status.Tags(), "setup is loaded only on demand")
+
+	status = r.LoadStatus(LoadOpts{Setup: true})
+	assert.Empty(t, status.Errors())
+	assert.Equal(t, 1, status.Depth())
+	assert.Equal(t, []string{"docs"}, status.SparsePaths())
+	assert.Equal(t, []string{"work", "go"}, status.Tags())
+	assert.Equal(t, "origin", status.TrackedRemote())
+
+	assert.FileExists(t, filepath.Join(r.Path(), "docs", "file"))
+	assert.NoFileExists(t, filepath.Join(r.Path(), "src", "file"))
+
+	assert.NoError(t, r.SetTags([]string{"oss"}))
+	tags, err := r.Tags()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"oss"}, tags)
+
+	pinned, err := r.Pinned()
+	assert.NoError(t, err)
+	assert.True(t, pinned)
+}
+
+func TestSetupOfFullClone(t *testing.T) {
+	t.Parallel()
+
+	r, _ := Open(test.RepoWithCommit(t).Path())
+
+	status := r.LoadStatus(LoadOpts{Setup: true})
+	assert.Empty(t, status.Errors())
+	assert.Zero(t, status.Depth())
+	assert.Nil(t, status.SparsePaths())
+	assert.Nil(t, status.Tags())
+	assert.Empty(t, status.TrackedRemote())
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"sort"
+	"time"
+)
+
+// Report contains information about branches and stashes of a repository which might need cleaning up.
+type Report struct {
+	path     string
+	gone     []string       // Branches whose upstream has been deleted.
+	merged   []string       // Branches fully merged into the default branch.
+	stale    []string       // Branches without commits for longer than the staleness threshold.
+	unpushed map[string]int // Branches without upstream and the number of their commits not existing on any remote.
+	stashes  int
+	errors   []string
+}
+
+// LoadReport reads the branches and stashes health of a repository.
+// Branches without any commits in the staleAfter period are reported as stale.
+// If errors occur during loading, they are stored in Report.errors slice.
+func (r *Repo) LoadReport(staleAfter time.Duration) *Report {
+	report := &Report{
+		path:     r.path,
+		unpushed: make(map[string]int),
+		errors:   make([]string, 0),
+	}
+
+	dates, err := r.LastCommitDates()
+	if err != nil {
+		report.errors = append(report.errors, err.Error())
+	}
+
+	// An empty repo doesn't have any branches (nor stashes) to report on.
+	if len(dates) == 0 {
+		return report
+	}
+
+	report.gone, err = r.GoneBranches()
+	if err != nil {
+		report.errors = append(report.errors, err.Error())
+	}
+
+	if base, err := r.DefaultBranch(); err != nil {
+		report.errors = append(report.errors, err.Error())
+	} else if report.merged, err = r.MergedBranches(base); err != nil {
+		report.errors = append(report.errors, err.Error())
+	}
+
+	for branch, date := range dates {
+		if time.Since(date) > staleAfter {
+			report.stale = append(report.stale, branch)
+		}
+
+		if upstream, _ := r.Upstream(branch); upstream != "" {
+			continue
+		}
+
+		count, err := r.Unpushed(branch)
+		if err != nil {
+			report.errors = append(report.errors, err.Error())
+
+			continue
+		}
+
+		if count > 0 {
+			report.unpushed[branch] = count
+		}
+	}
+
+	sort.Strings(report.stale)
+
+	report.stashes, err = r.Stashes()
+	if err != nil {
+		report.errors = append(report.errors, err.Error())
+	}
+
+	return report
+}
+
+// Path returns path to a repository.
+func (r *Report) Path() string {
+	return r.path
+}
+
+// Gone returns branches whose upstream has been deleted from the remote.
+func (r *Report) Gone() []string {
+	return r.gone
+}
+
+// Merged returns branches fully merged into the default branch.
+func (r *Report) Merged() []string {
+	return r.merged
+}
+
+// Stale returns branches without commits for longer than the staleness threshold.
+func (r *Report) Stale() []string {
+	return r.stale
+}
+
+// Unpushed returns branches without upstream which have commits not existing on any remote.
+func (r *Report) Unpushed() []string {
+	branches := make([]string, 0, len(r.unpushed))
+	for branch := range r.unpushed {
+		branches = append(branches, branch)
+	}
+
+	sort.Strings(branches)
+
+	return branches
+}
+
+// UnpushedCount returns the number of unpushed commits on a given branch.
+func (r *Report) UnpushedCount(branch string) int {
+	return r.unpushed[branch]
+}
+
+// Stashes returns the number of stash entries.
+func (r *Report) Stashes() int {
+	return r.stashes
+}
+
+// Errors is a slice of errors that occurred when loading the report.
+func (r *Report) Errors() []string {
+	return r.errors
+}
@@ -0,0 +1,128 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/grdl/git-get/pkg/run"
+)
+
+// Repo-local git config keys storing the tags of a repo (it can have multiple values)
+// and whether the repo was deliberately cloned into a custom path.
+const (
+	configTags   = "gitget.tags"
+	configPinned = "gitget.pinned"
+)
+
+// configValues returns all values of a given repo config key or nil if the key isn't set.
+func (r *Repo) configValues(key string) ([]string, error) {
+	out, err := run.Git("config", "--get-all", key).OnRepo(r.path).AndCaptureLines()
+	if err != nil {
+		// git config exits with code 1 when the key is not set.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Tags returns the tags (eg, "work" or "oss") assigned to the repo with SetTags.
+func (r *Repo) Tags() ([]string, error) {
+	return r.configValues(configTags)
+}
+
+// SetTags replaces the tags of the repo. They are stored in the repo's git config, so they are kept when it's moved.
+func (r *Repo) SetTags(tags []string) error {
+	current, err := r.Tags()
+	if err != nil {
+		return err
+	}
+
+	if len(current) > 0 {
+		if err := run.Git("config", "--unset-all", configTags).OnRepo(r.path).AndShutUp(); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range tags {
+		if err := run.Git("config", "--add", configTags, tag).OnRepo(r.path).AndShutUp(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Pinned checks if the repo was cloned into a custom path (eg, with the "path=" option of a dump file) and marked with SetPinned,
+// so its path isn't expected to match its remote URL.
+func (r *Repo) Pinned() (bool, error) {
+	pinned, err := r.configValues(configPinned)
+	if err != nil || len(pinned) == 0 {
+		return false, err
+	}
+
+	return strconv.ParseBool(pinned[len(pinned)-1])
+}
+
+// SetPinned marks the repo as deliberately placed at its current path. It's stored in the repo's git config.
+func (r *Repo) SetPinned() error {
+	return run.Git("config", configPinned, "true").OnRepo(r.path).AndShutUp()
+}
+
+// TrackedRemote returns the name of the remote tracked by the current branch or an empty string if it doesn't track any.
+func (r *Repo) TrackedRemote() (string, error) {
+	current, err := r.CurrentBranch()
+	if err != nil || current == head {
+		return "", err
+	}
+
+	remote, err := r.configValues(fmt.Sprintf("branch.%s.remote", current))
+	if err != nil || len(remote) == 0 {
+		return "", err
+	}
+
+	return remote[0], nil
+}
+
+// Depth returns the number of commits reachable from HEAD if the repo is a shallow clone, or zero if it has the full history.
+func (r *Repo) Depth() (int, error) {
+	shallow, err := run.Git("rev-parse", "--is-shallow-repository").OnRepo(r.path).AndCaptureLine()
+	if err != nil || shallow != "true" {
+		return 0, err
+	}
+
+	out, err := run.Git("rev-list", "--count", head).OnRepo(r.path).AndCaptureLine()
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(out)
+}
+
+// SparsePaths returns the directories checked out in a sparse checkout or nil if the whole worktree is checked out.
+func (r *Repo) SparsePaths() ([]string, error) {
+	sparse, err := r.configValues("core.sparseCheckout")
+	if err != nil || len(sparse) == 0 || sparse[0] != "true" {
+		return nil, err
+	}
+
+	out, err := run.Git("sparse-checkout", "list").OnRepo(r.path).AndCaptureLines()
+	if err != nil || len(out) == 1 && out[0] == "" {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SetSparsePaths limits the worktree to given directories (and the files at the top level) using a cone mode sparse checkout.
+func (r *Repo) SetSparsePaths(paths []string) error {
+	args := append([]string{"sparse-checkout", "set", "--cone"}, paths...)
+
+	return run.Git(args...).OnRepo(r.path).AndShutUp()
+}
@@ -1,31 +1,69 @@
 package git
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Status contains human readable (and printable) representation of a git repository status.
 type Status struct {
-	path     string
-	current  string
-	branches map[string]string // key: branch name, value: branch status
-	worktree string
-	remote   string
-	errors   []string // Slice of errors which occurred when loading the status.
+	path      string
+	current   string
+	branches  map[string]string // key: branch name, value: branch status
+	upstreams map[string]string // key: branch name, value: upstream branch, eg "origin/main"
+	worktree  string
+	ahead     int // Commits on the current branch missing from its upstream.
+	behind    int // Commits on the upstream missing from the current branch.
+	changes   int // Number of uncommitted and untracked files.
+	stashes   int
+	operation string // Operation in progress, eg "REBASING 3/7".
+	remote    string
+	remotes   []RemoteStatus
+	tracked   string   // Remote tracked by the current branch.
+	depth     int      // Depth of a shallow clone, zero if it has the full history.
+	sparse    []string // Directories checked out in a sparse checkout.
+	tags      []string
+	commit    Commit    // Currently checked out commit.
+	activity  time.Time // Time of the last local activity: the last commit or the last worktree change, whichever is later.
+	usage     DiskUsage // Loaded only when requested with LoadOpts.DiskUsage.
+	errors    []string  // Slice of errors which occurred when loading the status.
+}
+
+// RemoteStatus contains the URL of a remote and how the current branch compares with the remote's default branch.
+type RemoteStatus struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"` // Default branch of the remote, eg "upstream/main". Empty if it's not known.
+	Ahead  int    `json:"ahead"`
+	Behind int    `json:"behind"`
+}
+
+// LoadOpts controls what is done, on top of reading the status, when loading the status of a repository.
+type LoadOpts struct {
+	// Fetch from the remote repo before loading the status.
+	Fetch bool
+	// DiskUsage calculates the space taken by the repo on disk. It's slow on big repos so it's done only on demand.
+	DiskUsage bool
+	// LastCommit loads the last commit and the time of the last local activity.
+	// Finding the last worktree change runs another "git status" so it's done only on demand.
+	LastCommit bool
+	// Setup loads the options the repo was set up with: tracked remote, depth, sparse paths and tags.
+	Setup bool
 }
 
 // LoadStatus reads status of a repository.
-// If fetch equals true, it first fetches from the remote repo before loading the status.
 // If errors occur during loading, they are stored in Status.errors slice.
-func (r *Repo) LoadStatus(fetch bool) *Status {
+func (r *Repo) LoadStatus(opts LoadOpts) *Status {
 	status := &Status{
-		path:     r.path,
-		branches: make(map[string]string),
-		errors:   make([]string, 0),
+		path:      r.path,
+		branches:  make(map[string]string),
+		upstreams: make(map[string]string),
+		errors:    make([]string, 0),
 	}
 
-	if fetch {
+	if opts.Fetch {
 		if err := r.Fetch(); err != nil {
 			status.errors = append(status.errors, err.Error())
 		}
@@ -38,14 +76,21 @@ func (r *Repo) LoadStatus(fetch bool) *Status {
 		status.errors = append(status.errors, err.Error())
 	}
 
-	var errs []error
+	for _, err := range r.loadBranches(status) {
+		status.errors = append(status.errors, err.Error())
+	}
+
+	status.worktree, status.changes, err = r.loadWorkTree()
+	if err != nil {
+		status.errors = append(status.errors, err.Error())
+	}
 
-	status.branches, errs = r.loadBranches()
-	for _, err := range errs {
+	status.stashes, err = r.Stashes()
+	if err != nil {
 		status.errors = append(status.errors, err.Error())
 	}
 
-	status.worktree, err = r.loadWorkTree()
+	status.operation, err = r.Operation()
 	if err != nil {
 		status.errors = append(status.errors, err.Error())
 	}
@@ -55,51 +100,185 @@ func (r *Repo) LoadStatus(fetch bool) *Status {
 		status.errors = append(status.errors, err.Error())
 	}
 
+	if opts.LastCommit {
+		status.commit, status.activity, err = r.loadActivity()
+		if err != nil {
+			status.errors = append(status.errors, err.Error())
+		}
+	}
+
+	for _, err := range r.loadRemotes(status) {
+		status.errors = append(status.errors, err.Error())
+	}
+
+	if opts.Setup {
+		for _, err := range r.loadSetup(status) {
+			status.errors = append(status.errors, err.Error())
+		}
+	}
+
+	if opts.DiskUsage {
+		status.usage, err = r.DiskUsage()
+		if err != nil {
+			status.errors = append(status.errors, err.Error())
+		}
+	}
+
 	return status
 }
 
-func (r *Repo) loadBranches() (map[string]string, []error) {
-	statuses := make(map[string]string)
+// loadBranches loads statuses of all branches into status.branches.
+// Ahead and behind counts of the current branch are stored in status.ahead and status.behind.
+func (r *Repo) loadBranches(status *Status) []error {
 	errors := make([]error, 0)
 
 	branches, err := r.Branches()
 	if err != nil {
 		errors = append(errors, err)
 
-		return statuses, errors
+		return errors
 	}
 
 	for _, branch := range branches {
-		status, err := r.loadBranchStatus(branch)
-		statuses[branch] = status
+		upstream, branchStatus, ahead, behind, err := r.loadBranchStatus(branch)
+		status.branches[branch] = branchStatus
+
+		if upstream != "" {
+			status.upstreams[branch] = upstream
+		}
+
+		if branch == status.current {
+			status.ahead, status.behind = ahead, behind
+		}
 
 		if err != nil {
 			errors = append(errors, err)
 		}
 	}
 
-	return statuses, errors
+	return errors
 }
 
-func (r *Repo) loadBranchStatus(branch string) (string, error) {
-	upstream, err := r.Upstream(branch)
+func (r *Repo) loadBranchStatus(branch string) (upstream string, status string, ahead int, behind int, err error) {
+	upstream, err = r.Upstream(branch)
 	if err != nil {
-		return "", err
+		return "", "", 0, 0, err
 	}
 
 	if upstream == "" {
-		return "no upstream", nil
+		return "", "no upstream", 0, 0, nil
+	}
+
+	ahead, behind, err = r.AheadBehind(branch, upstream)
+	if err != nil {
+		return upstream, "", 0, 0, err
+	}
+
+	return upstream, aheadBehind(ahead, behind), ahead, behind, nil
+}
+
+// loadActivity returns the last commit and the time of the last local activity: the last commit or the last worktree change, whichever is later.
+func (r *Repo) loadActivity() (Commit, time.Time, error) {
+	commit, err := r.LastCommit()
+	if err != nil {
+		return commit, time.Time{}, err
 	}
 
-	ahead, behind, err := r.AheadBehind(branch, upstream)
+	activity, err := r.LastWorkTreeChange()
 	if err != nil {
-		return "", err
+		return commit, time.Time{}, err
 	}
 
-	if ahead == 0 && behind == 0 {
-		return "", nil
+	if commit.Date.After(activity) {
+		activity = commit.Date
+	}
+
+	return commit, activity, nil
+}
+
+// loadRemotes loads all remotes into status.remotes.
+// If there's more than one remote, the current commit is compared with their default branches.
+// With a single remote the status of branches is enough, so the comparison is skipped to save the git calls.
+func (r *Repo) loadRemotes(status *Status) []error {
+	errors := make([]error, 0)
+
+	remotes, err := r.Remotes()
+	if err != nil {
+		errors = append(errors, err)
+
+		return errors
+	}
+
+	compare := len(remotes) > 1
+	if compare && status.commit.Hash == "" {
+		// An empty repo doesn't have a commit to compare.
+		_, err := r.ResolveCommit(head)
+		compare = err == nil
+	}
+
+	for _, remote := range remotes {
+		rs := RemoteStatus{Name: remote.Name, URL: remote.URL}
+
+		if compare {
+			for _, err := range r.compareRemote(&rs) {
+				errors = append(errors, err)
+			}
+		}
+
+		status.remotes = append(status.remotes, rs)
+	}
+
+	return errors
+}
+
+// compareRemote finds the default branch of the remote and compares the current commit with it.
+func (r *Repo) compareRemote(rs *RemoteStatus) []error {
+	errors := make([]error, 0)
+
+	var err error
+
+	rs.Branch, err = r.RemoteDefaultBranch(rs.Name)
+	if err != nil {
+		errors = append(errors, err)
+	}
+
+	if rs.Branch != "" {
+		rs.Ahead, rs.Behind, err = r.AheadBehind(head, rs.Branch)
+		if err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	return errors
+}
+
+// loadSetup loads the options the repo was set up with, which can be restored when cloning it from a dump file.
+func (r *Repo) loadSetup(status *Status) []error {
+	errors := make([]error, 0)
+
+	var err error
+
+	if status.tracked, err = r.TrackedRemote(); err != nil {
+		errors = append(errors, err)
 	}
 
+	if status.depth, err = r.Depth(); err != nil {
+		errors = append(errors, err)
+	}
+
+	if status.sparse, err = r.SparsePaths(); err != nil {
+		errors = append(errors, err)
+	}
+
+	if status.tags, err = r.Tags(); err != nil {
+		errors = append(errors, err)
+	}
+
+	return errors
+}
+
+// aheadBehind returns a description of the ahead and behind counts (eg, "1 ahead 2 behind") or an empty string if both are zero.
+func aheadBehind(ahead int, behind int) string {
 	var res []string
 	if ahead != 0 {
 		res = append(res, fmt.Sprintf("%d ahead", ahead))
@@ -109,22 +288,23 @@ func (r *Repo) loadBranchStatus(branch string) (string, error) {
 		res = append(res, fmt.Sprintf("%d behind", behind))
 	}
 
-	return strings.Join(res, " "), nil
+	return strings.Join(res, " ")
 }
 
-func (r *Repo) loadWorkTree() (string, error) {
+// loadWorkTree returns the worktree status and the total number of uncommitted and untracked files.
+func (r *Repo) loadWorkTree() (string, int, error) {
 	uncommitted, err := r.Uncommitted()
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
 	untracked, err := r.Untracked()
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
 	if uncommitted == 0 && untracked == 0 {
-		return "", nil
+		return "", 0, nil
 	}
 
 	var res []string
@@ -136,7 +316,7 @@ func (r *Repo) loadWorkTree() (string, error) {
 		res = append(res, fmt.Sprintf("%d untracked", untracked))
 	}
 
-	return strings.Join(res, " "), nil
+	return strings.Join(res, " "), uncommitted + untracked, nil
 }
 
 // Path returns path to a repository.
@@ -166,17 +346,242 @@ func (s *Status) BranchStatus(branch string) string {
 	return s.branches[branch]
 }
 
+// Upstream returns the upstream branch tracked by a given branch (eg, "origin/main") or an empty string if it doesn't track any.
+func (s *Status) Upstream(branch string) string {
+	return s.upstreams[branch]
+}
+
 // WorkTreeStatus returns status of a worktree.
 func (s *Status) WorkTreeStatus() string {
 	return s.worktree
 }
 
+// Ahead returns the number of commits on the current branch which are missing from its upstream.
+func (s *Status) Ahead() int {
+	return s.ahead
+}
+
+// Behind returns the number of commits on the upstream of the current branch which are missing from it.
+func (s *Status) Behind() int {
+	return s.behind
+}
+
+// Changes returns the number of uncommitted and untracked files in the worktree.
+func (s *Status) Changes() int {
+	return s.changes
+}
+
+// Stashes returns the number of stash entries.
+func (s *Status) Stashes() int {
+	return s.stashes
+}
+
+// Operation returns the operation in progress (eg, "REBASING 3/7", "MERGING") or an empty string if there's none.
+func (s *Status) Operation() string {
+	return s.operation
+}
+
 // Remote returns URL to remote repository.
 func (s *Status) Remote() string {
 	return s.remote
 }
 
+// Remotes returns the names of all remotes. The "origin" remote goes first, the rest is sorted by name.
+func (s *Status) Remotes() []string {
+	names := make([]string, len(s.remotes))
+	for i, remote := range s.remotes {
+		names[i] = remote.Name
+	}
+
+	return names
+}
+
+// RemoteURL returns the URL of a given remote.
+func (s *Status) RemoteURL(name string) string {
+	if rs, ok := s.findRemote(name); ok {
+		return rs.URL
+	}
+
+	return ""
+}
+
+// RemoteBranch returns the default branch of a given remote (eg, "upstream/main") or an empty string if it's not known.
+// Default branches are loaded only for repos with more than one remote.
+func (s *Status) RemoteBranch(name string) string {
+	rs, _ := s.findRemote(name)
+
+	return rs.Branch
+}
+
+// RemoteStatus returns how the current commit compares with the default branch of a given remote, eg "1 ahead 2 behind".
+// It's an empty string if they are even or if the repo has a single remote, which isn't compared.
+func (s *Status) RemoteStatus(name string) string {
+	rs, ok := s.findRemote(name)
+	if !ok || len(s.remotes) < 2 {
+		return ""
+	}
+
+	if rs.Branch == "" {
+		return "no default branch"
+	}
+
+	return aheadBehind(rs.Ahead, rs.Behind)
+}
+
+func (s *Status) findRemote(name string) (RemoteStatus, bool) {
+	for _, rs := range s.remotes {
+		if rs.Name == name {
+			return rs, true
+		}
+	}
+
+	return RemoteStatus{}, false
+}
+
+// TrackedRemote returns the name of the remote tracked by the current branch or an empty string if it doesn't track any.
+func (s *Status) TrackedRemote() string {
+	return s.tracked
+}
+
+// Depth returns the number of commits in a shallow clone or zero if the repo has the full history.
+func (s *Status) Depth() int {
+	return s.depth
+}
+
+// SparsePaths returns the directories checked out in a sparse checkout or nil if the whole worktree is checked out.
+func (s *Status) SparsePaths() []string {
+	return s.sparse
+}
+
+// Tags returns the tags assigned to the repo.
+func (s *Status) Tags() []string {
+	return s.tags
+}
+
+// LastCommitDate returns the date of the currently checked out commit.
+func (s *Status) LastCommitDate() time.Time {
+	return s.commit.Date
+}
+
+// LastCommitAuthor returns the author of the currently checked out commit.
+func (s *Status) LastCommitAuthor() string {
+	return s.commit.Author
+}
+
+// LastCommitSubject returns the subject of the currently checked out commit.
+func (s *Status) LastCommitSubject() string {
+	return s.commit.Subject
+}
+
+// LastActivity returns the time of the last local activity in the repo: the last commit or the last worktree change, whichever is later.
+func (s *Status) LastActivity() time.Time {
+	return s.activity
+}
+
+// WorkTreeSize returns the size in bytes of files in the worktree. It's zero if disk usage wasn't loaded.
+func (s *Status) WorkTreeSize() int64 {
+	return s.usage.WorkTree
+}
+
+// GitSize returns the size in bytes of the .git directory without the LFS objects. It's zero if disk usage wasn't loaded.
+func (s *Status) GitSize() int64 {
+	return s.usage.Git
+}
+
+// LFSSize returns the size in bytes of the Git LFS objects. It's zero if disk usage wasn't loaded.
+func (s *Status) LFSSize() int64 {
+	return s.usage.LFS
+}
+
+// Size returns the total disk usage of the repo in bytes. It's zero if disk usage wasn't loaded.
+func (s *Status) Size() int64 {
+	return s.usage.Total()
+}
+
 // Errors is a slice of errors that occurred when loading repo status.
 func (s *Status) Errors() []string {
 	return s.errors
 }
+
+// statusJSON is a serializable representation of Status. It's used to pass statuses between the daemon and its clients.
+type statusJSON struct {
+	Path      string            `json:"path"`
+	Current   string            `json:"current"`
+	Branches  map[string]string `json:"branches"`
+	Upstreams map[string]string `json:"upstreams"`
+	WorkTree  string            `json:"worktree"`
+	Ahead     int               `json:"ahead"`
+	Behind    int               `json:"behind"`
+	Changes   int               `json:"changes"`
+	Stashes   int               `json:"stashes"`
+	Operation string            `json:"operation"`
+	Remote    string            `json:"remote"`
+	Remotes   []RemoteStatus    `json:"remotes"`
+	Tracked   string            `json:"tracked"`
+	Depth     int               `json:"depth"`
+	Sparse    []string          `json:"sparse"`
+	Tags      []string          `json:"tags"`
+	Commit    Commit            `json:"commit"`
+	Activity  time.Time         `json:"activity"`
+	Usage     DiskUsage         `json:"usage"`
+	Errors    []string          `json:"errors"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(statusJSON{
+		Path:      s.path,
+		Current:   s.current,
+		Branches:  s.branches,
+		Upstreams: s.upstreams,
+		WorkTree:  s.worktree,
+		Ahead:     s.ahead,
+		Behind:    s.behind,
+		Changes:   s.changes,
+		Stashes:   s.stashes,
+		Operation: s.operation,
+		Remote:    s.remote,
+		Remotes:   s.remotes,
+		Tracked:   s.tracked,
+		Depth:     s.depth,
+		Sparse:    s.sparse,
+		Tags:      s.tags,
+		Commit:    s.commit,
+		Activity:  s.activity,
+		Usage:     s.usage,
+		Errors:    s.errors,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var sj statusJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+
+	*s = Status{
+		path:      sj.Path,
+		current:   sj.Current,
+		branches:  sj.Branches,
+		upstreams: sj.Upstreams,
+		worktree:  sj.WorkTree,
+		ahead:     sj.Ahead,
+		behind:    sj.Behind,
+		changes:   sj.Changes,
+		stashes:   sj.Stashes,
+		operation: sj.Operation,
+		remote:    sj.Remote,
+		remotes:   sj.Remotes,
+		tracked:   sj.Tracked,
+		depth:     sj.Depth,
+		sparse:    sj.Sparse,
+		tags:      sj.Tags,
+		commit:    sj.Commit,
+		activity:  sj.Activity,
+		usage:     sj.Usage,
+		errors:    sj.Errors,
+	}
+
+	return nil
+}
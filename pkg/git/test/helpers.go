@@ -125,6 +125,36 @@ func (r *Repo) clone() *Repo {
 	return clone
 }
 
+func (r *Repo) deleteBranch(name string) {
+	err := run.Git("branch", "-D", name).OnRepo(r.path).AndShutUp()
+	checkFatal(r.t, err)
+	r.syncGitIndex()
+}
+
+func (r *Repo) stash() {
+	err := run.Git("stash").OnRepo(r.path).AndShutUp()
+	checkFatal(r.t, err)
+	r.syncGitIndex()
+}
+
+func (r *Repo) fetchPrune() {
+	err := run.Git("fetch", "--all", "--prune").OnRepo(r.path).AndShutUp()
+	checkFatal(r.t, err)
+	r.syncGitIndex()
+}
+
+// gitMayFail runs a git command which is expected to fail, eg a merge stopped by conflicts.
+func (r *Repo) gitMayFail(args ...string) {
+	_ = run.Git(args...).OnRepo(r.path).AndShutUp()
+	r.syncGitIndex()
+}
+
+func (r *Repo) addRemote(name string, url string) {
+	err := run.Git("remote", "add", name, url).OnRepo(r.path).AndShutUp()
+	checkFatal(r.t, err)
+	r.syncGitIndex()
+}
+
 func (r *Repo) fetch() {
 	err := run.Git("fetch", "--all").OnRepo(r.path).AndShutUp()
 	checkFatal(r.t, err)
@@ -1,8 +1,11 @@
 package test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/grdl/git-get/pkg/run"
 )
 
 // Repo represents a test repository.
@@ -186,6 +189,163 @@ func RepoWithBranchAheadAndBehind(t *testing.T) *Repo {
 	return r
 }
 
+// RepoWithMergedBranch creates a git repo with "feature/merged" branch merged into main and "feature/unmerged" branch with an extra commit.
+func RepoWithMergedBranch(t *testing.T) *Repo {
+	t.Helper()
+	r := RepoWithCommit(t)
+	r.branch("feature/merged")
+	r.branch("feature/unmerged")
+	r.checkout("feature/unmerged")
+
+	r.writeFile("unmerged.txt", "unmerged")
+	r.stageFile("unmerged.txt")
+	r.commit("unmerged")
+
+	r.checkout("main")
+
+	return r
+}
+
+// RepoWithGoneUpstream creates a git repo with "feature/branch" branch whose upstream has been deleted from the remote.
+func RepoWithGoneUpstream(t *testing.T) *Repo {
+	t.Helper()
+	origin := RepoWithCommit(t)
+	origin.branch("feature/branch")
+
+	r := origin.clone()
+	r.checkout("feature/branch")
+	r.checkout("main")
+
+	origin.deleteBranch("feature/branch")
+	r.fetchPrune()
+
+	return r
+}
+
+// RepoWithStash creates a git repo with two stash entries.
+func RepoWithStash(t *testing.T) *Repo {
+	t.Helper()
+	r := RepoWithCommit(t)
+
+	r.writeFile("README.md", "first stashed change")
+	r.stash()
+
+	r.writeFile("README.md", "second stashed change")
+	r.stash()
+
+	return r
+}
+
+// RepoWithUpstreamRemote creates a fork-like git repo cloned from "origin", with an "upstream" remote which is 1 commit ahead of origin.
+func RepoWithUpstreamRemote(t *testing.T) *Repo {
+	t.Helper()
+	origin := RepoWithCommit(t)
+	upstream := origin.clone()
+
+	upstream.writeFile("upstream.new", "upstream.new")
+	upstream.stageFile("upstream.new")
+	upstream.commit("upstream.new")
+
+	r := origin.clone()
+	r.addRemote("upstream", upstream.path)
+	r.fetch()
+
+	return r
+}
+
+// RepoWithFile creates a git repo with a single commit adding a file with given content.
+func RepoWithFile(t *testing.T, name string, content string) *Repo {
+	t.Helper()
+	r := RepoEmpty(t)
+	r.writeFile(name, content)
+	r.stageFile(name)
+	r.commit("Add " + name)
+
+	return r
+}
+
+// CommitFile commits a new content of a file into the repo.
+func (r *Repo) CommitFile(name string, content string) {
+	r.writeFile(name, content)
+	r.stageFile(name)
+	r.commit("Update " + name)
+}
+
+// RepoWithDirs creates a git repo with two commits, the second one adds files in "docs" and "src" directories.
+func RepoWithDirs(t *testing.T) *Repo {
+	t.Helper()
+	r := RepoWithCommit(t)
+
+	for _, dir := range []string{"docs", "src"} {
+		checkFatal(t, os.Mkdir(filepath.Join(r.path, dir), 0755))
+		r.writeFile(filepath.Join(dir, "file"), dir)
+		r.stageFile(dir)
+	}
+
+	r.commit("Add dirs")
+
+	return r
+}
+
+// repoWithConflictingBranch creates a git repo with main and "feature/branch" branches, both changing the same line of README.md.
+func repoWithConflictingBranch(t *testing.T) *Repo {
+	t.Helper()
+	r := RepoWithCommit(t)
+	r.branch("feature/branch")
+	r.checkout("feature/branch")
+
+	r.writeFile("README.md", "changed on feature/branch")
+	r.stageFile("README.md")
+	r.commit("feature change")
+
+	r.checkout("main")
+	r.writeFile("README.md", "changed on main")
+	r.stageFile("README.md")
+	r.commit("main change")
+
+	return r
+}
+
+// RepoMerging creates a git repo with a merge stopped by a conflict.
+func RepoMerging(t *testing.T) *Repo {
+	t.Helper()
+	r := repoWithConflictingBranch(t)
+	r.gitMayFail("merge", "feature/branch")
+
+	return r
+}
+
+// RepoRebasing creates a git repo with a rebase stopped by a conflict on the first of one commits.
+func RepoRebasing(t *testing.T) *Repo {
+	t.Helper()
+	r := repoWithConflictingBranch(t)
+	r.checkout("feature/branch")
+	r.gitMayFail("rebase", "--merge", "main")
+
+	return r
+}
+
+// RepoCherryPicking creates a git repo with a cherry-pick stopped by a conflict.
+func RepoCherryPicking(t *testing.T) *Repo {
+	t.Helper()
+	r := repoWithConflictingBranch(t)
+	r.gitMayFail("cherry-pick", "feature/branch")
+
+	return r
+}
+
+// RepoBisecting creates a git repo with a bisect in progress.
+func RepoBisecting(t *testing.T) *Repo {
+	t.Helper()
+	r := RepoWithCommit(t)
+
+	// Bisect needs to be run from inside the worktree, "--work-tree" used by OnRepo() isn't enough.
+	err := run.Git("-C", r.path, "bisect", "start").AndShutUp()
+	checkFatal(t, err)
+
+	return r
+}
+
 // RepoWithEmptyConfig creates a git repo with empty .git/config file.
 func RepoWithEmptyConfig(t *testing.T) *Repo {
 	t.Helper()
@@ -210,3 +370,30 @@ func RepoWithValidConfig(t *testing.T) *Repo {
 
 	return r
 }
+
+// RepoWithIncludedConfig creates a git repo whose .git/config file includes another file with gitget keys.
+func RepoWithIncludedConfig(t *testing.T) *Repo {
+	t.Helper()
+	r := RepoEmpty(t)
+
+	gitconfig := `
+	[include]
+		path = gitget.config
+	[gitget]
+		host = github.com
+		root = /local/root
+	`
+	r.writeFile(filepath.Join(".git", "config"), gitconfig)
+
+	included := `
+	[gitget]
+		root = /included/root
+		skip-host
+		note = "first line\nsecond line"
+	[gitget "GitLab.example.com"]
+		Token = secret
+	`
+	r.writeFile(filepath.Join(".git", "gitget.config"), included)
+
+	return r
+}
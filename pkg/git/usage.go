@@ -0,0 +1,77 @@
+package git
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// DiskUsage contains the sizes (in bytes) of files taken by a repository on disk.
+type DiskUsage struct {
+	WorkTree int64 `json:"worktree"` // Files in the worktree, including ignored files and nested repos.
+	Git      int64 `json:"git"`      // Files in the .git directory except the LFS objects.
+	LFS      int64 `json:"lfs"`      // Git LFS objects stored in .git/lfs/objects.
+}
+
+// Total returns the total number of bytes used by the repository.
+func (u DiskUsage) Total() int64 {
+	return u.WorkTree + u.Git + u.LFS
+}
+
+// DiskUsage calculates how much disk space is taken by the repository.
+// Only regular files are counted and symlinks are not followed. Unreadable files and directories are skipped.
+func (r *Repo) DiskUsage() (DiskUsage, error) {
+	var usage DiskUsage
+
+	gitDir, err := r.gitDir()
+	if err != nil {
+		return usage, err
+	}
+
+	lfsDir := filepath.Join(gitDir, "lfs", "objects")
+
+	walkFiles(r.path, filepath.Join(r.path, dotgit), func(_ string, size int64) {
+		usage.WorkTree += size
+	})
+
+	walkFiles(gitDir, "", func(path string, size int64) {
+		if path == lfsDir || strings.HasPrefix(path, lfsDir+string(filepath.Separator)) {
+			usage.LFS += size
+		} else {
+			usage.Git += size
+		}
+	})
+
+	return usage, nil
+}
+
+// walkFiles calls fn with the path and size of every regular file under root, except the ones under the skip path.
+func walkFiles(root string, skip string, fn func(path string, size int64)) {
+	//nolint:errcheck // The callback never returns an error.
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // Unreadable files are skipped, same as in RepoFinder.Find().
+		}
+
+		if path == skip {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil //nolint:nilerr // The file might have been removed in the meantime.
+		}
+
+		fn(path, info.Size())
+
+		return nil
+	})
+}
@@ -0,0 +1,233 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/grdl/git-get/pkg/run"
+)
+
+// Default time to wait for more filesystem events before reloading a changed repo.
+// Git operations usually touch many files in a quick succession so we want to reload only once they settle down.
+const debounceDelay = 200 * time.Millisecond
+
+// Watcher watches git repositories for filesystem changes and reloads the status of the repos which changed.
+// It watches both the .git directory (HEAD, index, refs) and the worktree directories of each repo.
+// Inotify (and friends) watches are not recursive, so every directory is watched separately.
+type Watcher struct {
+	fsw     *fsnotify.Watcher
+	repos   []*Repo // Sorted by path length, longest first, so nested paths are matched before their parents.
+	updates chan []*Status
+	delay   time.Duration
+	opts    LoadOpts
+}
+
+// NewWatcher creates a Watcher for given repos. Changed repos have their status loaded with given opts.
+// Call Run to start processing the events.
+func NewWatcher(repos []*Repo, opts LoadOpts) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed creating filesystem watcher: %w", err)
+	}
+
+	w := &Watcher{
+		fsw:     fsw,
+		repos:   append([]*Repo{}, repos...),
+		updates: make(chan []*Status),
+		delay:   debounceDelay,
+		opts:    opts,
+	}
+
+	sort.Slice(w.repos, func(i, j int) bool {
+		return len(w.repos[i].path) > len(w.repos[j].path)
+	})
+
+	for _, repo := range w.repos {
+		if err := w.addRepo(repo); err != nil {
+			fsw.Close()
+
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// Updates returns a channel on which freshly reloaded statuses of changed repos are sent.
+// The channel is closed when Run returns.
+func (w *Watcher) Updates() <-chan []*Status {
+	return w.updates
+}
+
+// Run processes filesystem events until the context is cancelled.
+// Events are debounced per repo and each changed repo has its status reloaded exactly once per batch of events.
+func (w *Watcher) Run(ctx context.Context) error {
+	defer close(w.updates)
+	defer w.fsw.Close()
+
+	dirty := make(map[*Repo]bool)
+	timer := time.NewTimer(w.delay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return nil
+			}
+
+			repo := w.handleEvent(event)
+			if repo == nil {
+				continue
+			}
+
+			dirty[repo] = true
+
+			timer.Reset(w.delay)
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil
+			}
+
+			// When the kernel queue overflows we don't know which repos changed so we reload all of them.
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				return fmt.Errorf("filesystem watcher failed: %w", err)
+			}
+
+			for _, repo := range w.repos {
+				dirty[repo] = true
+			}
+
+			timer.Reset(w.delay)
+
+		case <-timer.C:
+			statuses := make([]*Status, 0, len(dirty))
+			for repo := range dirty {
+				statuses = append(statuses, repo.LoadStatus(w.opts))
+			}
+
+			clear(dirty)
+
+			select {
+			case w.updates <- statuses:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// handleEvent finds the repo affected by a filesystem event. Returns nil if the event should be ignored.
+// If a new directory appears in the worktree, it starts watching it too.
+func (w *Watcher) handleEvent(event fsnotify.Event) *Repo {
+	// Git creates *.lock files before atomically renaming them to the target file, the rename will trigger its own event.
+	if strings.HasSuffix(event.Name, ".lock") || event.Op == fsnotify.Chmod {
+		return nil
+	}
+
+	repo := w.findRepo(event.Name)
+	if repo == nil {
+		return nil
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			// Errors are ignored, in the worst case we miss changes in the new directory until the next full reload.
+			_ = w.addWorkTree(repo, event.Name)
+		}
+	}
+
+	return repo
+}
+
+// findRepo returns the repo containing the given path.
+func (w *Watcher) findRepo(path string) *Repo {
+	for _, repo := range w.repos {
+		if path == repo.path || strings.HasPrefix(path, repo.path+string(filepath.Separator)) {
+			return repo
+		}
+	}
+
+	return nil
+}
+
+// addRepo starts watching the .git directory with its refs and the worktree of a repo.
+func (w *Watcher) addRepo(repo *Repo) error {
+	gitDir := filepath.Join(repo.path, dotgit)
+
+	if err := w.fsw.Add(gitDir); err != nil {
+		return fmt.Errorf("failed watching %s: %w", gitDir, err)
+	}
+
+	err := filepath.WalkDir(filepath.Join(gitDir, "refs"), func(path string, dir fs.DirEntry, err error) error {
+		if err != nil || !dir.IsDir() {
+			return nil //nolint:nilerr // Unreadable refs are skipped, they will be picked up by the next full reload.
+		}
+
+		return w.fsw.Add(path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed watching refs of %s: %w", repo.path, err)
+	}
+
+	return w.addWorkTree(repo, repo.path)
+}
+
+// addWorkTree recursively watches directories of a worktree starting at a given dir.
+// It skips the .git directory, nested repositories and directories ignored by git (eg, node_modules).
+func (w *Watcher) addWorkTree(repo *Repo, dir string) error {
+	ignored := repo.ignoredDirs()
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil //nolint:nilerr // Unreadable dirs are skipped, same as in RepoFinder.Find().
+		}
+
+		if entry.Name() == dotgit || ignored[path] {
+			return fs.SkipDir
+		}
+
+		if path != repo.path {
+			if _, err := os.Stat(filepath.Join(path, dotgit)); err == nil {
+				return fs.SkipDir
+			}
+		}
+
+		return w.fsw.Add(path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed watching worktree of %s: %w", repo.path, err)
+	}
+
+	return nil
+}
+
+// ignoredDirs returns a set of absolute paths of directories ignored by git.
+func (r *Repo) ignoredDirs() map[string]bool {
+	ignored := make(map[string]bool)
+
+	out, err := run.Git("ls-files", "--others", "--ignored", "--exclude-standard", "--directory").OnRepo(r.path).AndCaptureLines()
+	if err != nil {
+		return ignored
+	}
+
+	for _, line := range out {
+		if strings.HasSuffix(line, "/") {
+			ignored[filepath.Join(r.path, filepath.FromSlash(strings.TrimSuffix(line, "/")))] = true
+		}
+	}
+
+	return ignored
+}
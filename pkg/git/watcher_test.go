@@ -0,0 +1,99 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		change   func(t *testing.T, path string)
+		worktree string
+	}{
+		{
+			name: "new untracked file",
+			change: func(t *testing.T, path string) {
+				t.Helper()
+				require.NoError(t, os.WriteFile(filepath.Join(path, "new.txt"), []byte("new"), 0644))
+			},
+			worktree: "1 untracked",
+		},
+		{
+			name: "file in new directory",
+			change: func(t *testing.T, path string) {
+				t.Helper()
+				require.NoError(t, os.MkdirAll(filepath.Join(path, "sub", "dir"), os.ModePerm))
+				time.Sleep(100 * time.Millisecond) // Give the watcher time to start watching the new directory.
+				require.NoError(t, os.WriteFile(filepath.Join(path, "sub", "dir", "new.txt"), []byte("new"), 0644))
+			},
+			worktree: "1 untracked",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, err := Open(makeRepoWithCommit(t))
+			require.NoError(t, err)
+
+			watcher, err := NewWatcher([]*Repo{repo}, LoadOpts{})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go watcher.Run(ctx) //nolint:errcheck
+
+			test.change(t, repo.Path())
+
+			// Multiple batches might be sent if events are spread in time, wait for the one with the expected status.
+			timeout := time.After(5 * time.Second)
+
+			for {
+				select {
+				case statuses := <-watcher.Updates():
+					require.Len(t, statuses, 1)
+					assert.Equal(t, repo.Path(), statuses[0].Path())
+
+					if statuses[0].WorkTreeStatus() == test.worktree {
+						return
+					}
+				case <-timeout:
+					t.Fatalf("didn't receive status update with worktree %q", test.worktree)
+				}
+			}
+		})
+	}
+}
+
+func TestWatcherFindRepo(t *testing.T) {
+	t.Parallel()
+
+	outer := &Repo{path: filepath.Join("root", "repo")}
+	inner := &Repo{path: filepath.Join("root", "repo", "nested")}
+	similar := &Repo{path: filepath.Join("root", "repo-other")}
+
+	watcher := &Watcher{repos: []*Repo{similar, inner, outer}}
+
+	assert.Equal(t, outer, watcher.findRepo(filepath.Join("root", "repo", "file.txt")))
+	assert.Equal(t, inner, watcher.findRepo(filepath.Join("root", "repo", "nested", "file.txt")))
+	assert.Equal(t, similar, watcher.findRepo(filepath.Join("root", "repo-other", ".git", "HEAD")))
+	assert.Nil(t, watcher.findRepo(filepath.Join("root", "unknown")))
+}
+
+func makeRepoWithCommit(t *testing.T) string {
+	t.Helper()
+
+	return test.RepoWithCommit(t).Path()
+}
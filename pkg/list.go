@@ -3,47 +3,305 @@ package pkg
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grdl/git-get/pkg/cfg"
 	"github.com/grdl/git-get/pkg/git"
 	"github.com/grdl/git-get/pkg/out"
 )
 
-var ErrInvalidOutput = errors.New("invalid output format")
+var (
+	ErrInvalidOutput  = errors.New("invalid output format")
+	ErrInvalidGroupBy = errors.New("invalid group-by value")
+	errInvalidAge     = errors.New("invalid age")
+)
+
+// Units accepted by parseAge on top of the ones supported by time.ParseDuration.
+var ageUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// ageSyntax matches ages like "180d" or "2w".
+var ageSyntax = regexp.MustCompile(`^(\d+)([dwy])$`)
 
 // ListCfg provides configuration for the List command.
 type ListCfg struct {
-	Fetch  bool
-	Output string
-	Root   string
+	Daemon        bool
+	DefHost       string
+	DefScheme     string
+	DiskUsage     bool
+	Fetch         bool
+	Fix           bool
+	GroupBy       string
+	LastCommit    bool
+	Misplaced     bool
+	OlderThan     string
+	Output        string
+	PullRequests  bool // Annotate branches with their pull requests and CI checks state loaded from forges.
+	Report        bool
+	Root          string
+	Routes        []cfg.Route // Roots other than Root for repos matching their patterns. Their repos are listed too.
+	SkipHost      bool
+	Socket        string
+	Sort          string
+	StaleDays     int
+	Tags          []string
+	Watch         bool
+	Interval      time.Duration
+	FetchInterval time.Duration
 }
 
 // List executes the "git list" command.
 func List(conf *ListCfg) error {
-	finder := git.NewRepoFinder(conf.Root)
-	if err := finder.Find(); err != nil {
+	if err := validate(conf); err != nil {
 		return err
 	}
 
-	statuses := finder.LoadAll(conf.Fetch)
+	if conf.Watch {
+		return watch(conf)
+	}
+
+	if conf.Report {
+		return report(conf)
+	}
 
-	printables := make([]out.Printable, len(statuses))
+	if conf.Misplaced || conf.Fix {
+		return misplaced(conf)
+	}
+
+	var (
+		statuses []*git.Status
+		err      error
+	)
+
+	opts := loadOpts(conf)
+	opts.Fetch = conf.Fetch
+
+	// The daemon only keeps the basic status of repos, so when fetching or any optional details are requested the statuses must be loaded directly.
+	// It serves a single root, so it's not used when there are more roots.
+	if conf.Daemon && opts == (git.LoadOpts{}) && len(conf.Routes) == 0 {
+		statuses, err = queryDaemon(conf.Socket, conf.Root)
+	}
+
+	if statuses == nil || err != nil {
+		statuses, err = loadRootsStatuses(allRoots(conf.Root, cfg.Roots(conf.Routes, conf.Root)), opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	printables := toPrintables(selectStatuses(conf, statuses))
+
+	// The dump output doesn't show branches, so there's no need to query forges.
+	if conf.PullRequests && conf.Output != cfg.OutDump {
+		printables = withForgeInfo(printables, newForgeProviders(cfg.WithHosts(&git.Config{})).forHost)
+	}
+
+	res, err := render(conf, printables)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(res)
+
+	return nil
+}
+
+// validate checks the values of flags which are used only after the statuses are loaded, so we can fail early.
+func validate(conf *ListCfg) error {
+	if err := validateSort(conf.Sort); err != nil {
+		return err
+	}
+
+	if conf.OlderThan != "" {
+		if _, err := parseAge(conf.OlderThan); err != nil {
+			return err
+		}
+	}
+
+	if conf.GroupBy != "" && !slices.Contains(cfg.AllowedGroupBy, conf.GroupBy) {
+		return fmt.Errorf("%w, allowed values: [%s]", ErrInvalidGroupBy, strings.Join(cfg.AllowedGroupBy, ", "))
+	}
+
+	if conf.GroupBy == cfg.GroupTag && conf.Output != cfg.OutTree {
+		return fmt.Errorf("%w: grouping by tag works only with the tree output", ErrInvalidGroupBy)
+	}
+
+	return nil
+}
+
+// selectStatuses filters the statuses with the --older-than and --tag flags and sorts them according to the --sort flag.
+// Flag values must be validated first. The given slice is not modified.
+func selectStatuses(conf *ListCfg, statuses []*git.Status) []*git.Status {
+	statuses = slices.DeleteFunc(slices.Clone(statuses), func(status *git.Status) bool {
+		return !hasAnyTag(status.Tags(), conf.Tags)
+	})
+
+	if conf.OlderThan != "" {
+		age, _ := parseAge(conf.OlderThan)
+		statuses = olderThan(statuses, age)
+	}
+
+	sortStatuses(statuses, conf.Sort)
+
+	return statuses
+}
+
+// olderThan returns statuses of repos without any local activity for longer than age.
+func olderThan(statuses []*git.Status, age time.Duration) []*git.Status {
+	var res []*git.Status
+
+	for _, status := range statuses {
+		if time.Since(status.LastActivity()) > age {
+			res = append(res, status)
+		}
+	}
+
+	return res
+}
+
+// parseAge parses an age like "180d", "2w" or "1y". Any value accepted by time.ParseDuration (eg, "36h") is also valid.
+func parseAge(age string) (time.Duration, error) {
+	if m := ageSyntax.FindStringSubmatch(age); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, fmt.Errorf("%w %q: %w", errInvalidAge, age, err)
+		}
+
+		return time.Duration(n) * ageUnits[m[2]], nil
+	}
+
+	d, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q, use a number followed by a unit, eg 180d, 8w or 1y", errInvalidAge, age)
+	}
+
+	return d, nil
+}
+
+// report prints the branches and stashes health report of all repositories.
+func report(conf *ListCfg) error {
+	var (
+		reports []*git.Report
+		errs    []error
+	)
+
+	for _, root := range allRoots(conf.Root, cfg.Roots(conf.Routes, conf.Root)) {
+		finder := git.NewRepoFinder(root)
+		if err := finder.Find(); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		reports = append(reports, finder.LoadReports(time.Duration(conf.StaleDays)*24*time.Hour)...)
+	}
+
+	// Like with the status, roots without repos are skipped unless none of them has any.
+	if len(reports) == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	reportables := make([]out.Reportable, len(reports))
+	for i := range reports {
+		reportables[i] = reports[i]
+	}
+
+	fmt.Print(out.NewReportPrinter().Print(reportables))
+
+	return nil
+}
+
+// loadOpts returns which optional details of repos are needed to print, filter or sort them according to the flags.
+// Loading them runs extra git commands on each repo, so they're loaded only when needed.
+func loadOpts(conf *ListCfg) git.LoadOpts {
+	sortKey := strings.TrimPrefix(conf.Sort, descPrefix)
+
+	return git.LoadOpts{
+		DiskUsage:  conf.DiskUsage || sortKey == cfg.SortSize,
+		LastCommit: conf.LastCommit || conf.OlderThan != "" || sortKey == cfg.SortAge || sortKey == cfg.SortDate,
+		Setup:      conf.Output == cfg.OutDump || len(conf.Tags) > 0 || conf.GroupBy == cfg.GroupTag,
+	}
+}
+
+// loadStatuses finds all repositories under root and loads their statuses.
+func loadStatuses(root string, opts git.LoadOpts) ([]*git.Status, error) {
+	finder := git.NewRepoFinder(root)
+	if err := finder.Find(); err != nil {
+		return nil, err
+	}
+
+	return finder.LoadAll(opts), nil
+}
 
+func toPrintables(statuses []*git.Status) []out.Printable {
+	printables := make([]out.Printable, len(statuses))
 	for i := range statuses {
 		printables[i] = statuses[i]
 	}
 
+	return printables
+}
+
+func printerOptions(conf *ListCfg) out.Options {
+	return out.Options{
+		DiskUsage:  conf.DiskUsage,
+		GroupByTag: conf.GroupBy == cfg.GroupTag,
+		LastCommit: conf.LastCommit,
+	}
+}
+
+// pathOverride returns a function which gives the path of a repo relative to the root, if it's not the path
+// where "git get" would clone it. Repos outside of the root get an absolute path.
+func pathOverride(conf *ListCfg) func(repo out.Printable) string {
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return nil
+	}
+
+	l := &layout{
+		root:      root,
+		routes:    conf.Routes,
+		defHost:   conf.DefHost,
+		defScheme: conf.DefScheme,
+		skipHost:  conf.SkipHost,
+	}
+
+	return func(repo out.Printable) string {
+		path, err := filepath.Abs(repo.Path())
+		if err != nil || repo.Remote() == "" {
+			return ""
+		}
+
+		if canonical, err := l.canonicalPath(repo.Remote()); err == nil && canonical == path {
+			return ""
+		}
+
+		if rel, err := filepath.Rel(root, path); err == nil && isInside(root, path) {
+			return filepath.ToSlash(rel)
+		}
+
+		return path
+	}
+}
+
+// render prints the printables using the printer selected by the --out flag.
+func render(conf *ListCfg, printables []out.Printable) (string, error) {
 	switch conf.Output {
 	case cfg.OutFlat:
-		fmt.Print(out.NewFlatPrinter().Print(printables))
+		return out.NewFlatPrinter(printerOptions(conf)).Print(printables), nil
 	case cfg.OutTree:
-		fmt.Print(out.NewTreePrinter().Print(conf.Root, printables))
+		return out.NewTreePrinter(printerOptions(conf)).PrintRoots(allRoots(conf.Root, cfg.Roots(conf.Routes, conf.Root)), printables), nil
 	case cfg.OutDump:
-		fmt.Print(out.NewDumpPrinter().Print(printables))
+		return out.NewDumpPrinter(pathOverride(conf)).Print(printables), nil
 	default:
-		return fmt.Errorf("%w, allowed values: [%s]", ErrInvalidOutput, strings.Join(cfg.AllowedOut, ", "))
+		return "", fmt.Errorf("%w, allowed values: [%s]", ErrInvalidOutput, strings.Join(cfg.AllowedOut, ", "))
 	}
-
-	return nil
 }
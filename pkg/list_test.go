@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"180d", 180 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"1y", 365 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"3x", 0, true},
+		{"-5d", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := parseAge(test.in)
+		if test.wantErr {
+			assert.ErrorIs(t, err, errInvalidAge, "age %q", test.in)
+
+			continue
+		}
+
+		assert.NoError(t, err, "age %q", test.in)
+		assert.Equal(t, test.want, got, "age %q", test.in)
+	}
+}
+
+func TestLoadOpts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		conf *ListCfg
+		want git.LoadOpts
+	}{
+		{&ListCfg{Output: cfg.OutTree}, git.LoadOpts{}},
+		{&ListCfg{Output: cfg.OutFlat, Sort: "-size"}, git.LoadOpts{DiskUsage: true}},
+		{&ListCfg{Output: cfg.OutFlat, LastCommit: true}, git.LoadOpts{LastCommit: true}},
+		{&ListCfg{Output: cfg.OutFlat, Sort: "age"}, git.LoadOpts{LastCommit: true}},
+		{&ListCfg{Output: cfg.OutFlat, Sort: "-date"}, git.LoadOpts{LastCommit: true}},
+		{&ListCfg{Output: cfg.OutFlat, OlderThan: "30d"}, git.LoadOpts{LastCommit: true}},
+		{&ListCfg{Output: cfg.OutDump}, git.LoadOpts{Setup: true}},
+		{&ListCfg{Output: cfg.OutFlat, Tags: []string{"work"}}, git.LoadOpts{Setup: true}},
+		{&ListCfg{Output: cfg.OutTree, GroupBy: cfg.GroupTag}, git.LoadOpts{Setup: true}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, loadOpts(test.conf), "%+v", test.conf)
+	}
+}
@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/grdl/git-get/pkg/git"
+)
+
+// liveStatuses keeps the statuses of all repos under the roots up to date.
+// All repos are reloaded with reload() and, in between, the repos changed on disk are reloaded by a filesystem watcher.
+// Receive from updates and pass the result to merge() to apply the changes detected by the watcher.
+// It's safe to call get() concurrently with reload() and merge().
+type liveStatuses struct {
+	roots []string
+	opts  git.LoadOpts // Optional details loaded with the statuses. Fetch is ignored, it's passed to reload().
+
+	mu       sync.RWMutex
+	statuses []*git.Status
+	err      error
+
+	repoPaths []string // Paths of repos currently watched for filesystem events.
+	updates   <-chan []*git.Status
+	stopWatch context.CancelFunc
+}
+
+// reload finds all repos and reloads all their statuses.
+// If the set of found repos changed, filesystem events watching is restarted.
+func (l *liveStatuses) reload(ctx context.Context, fetch bool) {
+	finders, err := findRoots(l.roots)
+	if err != nil {
+		l.mu.Lock()
+		l.statuses, l.err = nil, err
+		l.mu.Unlock()
+
+		return
+	}
+
+	opts := l.opts
+	opts.Fetch = fetch
+
+	statuses := loadFound(finders, opts)
+
+	l.mu.Lock()
+	l.statuses, l.err = statuses, nil
+	l.mu.Unlock()
+
+	paths := make([]string, len(statuses))
+	for i, status := range statuses {
+		paths[i] = status.Path()
+	}
+
+	if !slices.Equal(paths, l.repoPaths) {
+		l.startEvents(ctx, reposOf(finders), paths)
+	}
+}
+
+// startEvents (re)starts watching the repos for filesystem changes.
+// If the watcher can't be created (eg, inotify limits are reached), we fall back to periodic reloading only.
+func (l *liveStatuses) startEvents(ctx context.Context, repos []*git.Repo, paths []string) {
+	l.stopEvents()
+	l.repoPaths = paths
+
+	opts := l.opts
+	opts.Fetch = false
+
+	watcher, err := git.NewWatcher(repos, opts)
+	if err != nil {
+		return
+	}
+
+	ctx, l.stopWatch = context.WithCancel(ctx)
+	l.updates = watcher.Updates()
+
+	// On failure the updates channel gets closed and merge() makes the next reload() restart watching.
+	go func() {
+		if err := watcher.Run(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v, restarting it on the next reload\n", err)
+		}
+	}()
+}
+
+func (l *liveStatuses) stopEvents() {
+	if l.stopWatch != nil {
+		l.stopWatch()
+	}
+
+	l.stopWatch = nil
+	l.updates = nil
+}
+
+// merge replaces the statuses of repos reloaded by the filesystem watcher.
+// If the watcher stopped (ie, updated is nil because the channel got closed), it stops listening to its updates
+// and forgets the watched repos, so the next reload() restarts watching.
+func (l *liveStatuses) merge(updated []*git.Status, ok bool) {
+	if !ok {
+		l.stopEvents()
+		l.repoPaths = nil
+
+		return
+	}
+
+	byPath := make(map[string]*git.Status, len(updated))
+	for _, status := range updated {
+		byPath[status.Path()] = status
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	statuses := slices.Clone(l.statuses)
+	for i, status := range statuses {
+		if u, ok := byPath[status.Path()]; ok {
+			statuses[i] = u
+		}
+	}
+
+	l.statuses = statuses
+}
+
+// get returns the current statuses or the error which occurred when finding the repos.
+func (l *liveStatuses) get() ([]*git.Status, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.statuses, l.err
+}
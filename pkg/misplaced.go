@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/grdl/git-get/pkg/cfg"
+)
+
+// misplaced finds repos under the root and the roots of routes whose path doesn't match their remote URL
+// (eg, forks, renamed or transferred repos or repos cloned before their route was added) and prints where they should be moved.
+// If conf.Fix is true, it moves them there, also from one root into another.
+// Repos without a remote are ignored because their canonical path is unknown, and so are repos pinned to a custom path.
+func misplaced(conf *ListCfg) error {
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return err
+	}
+
+	roots := allRoots(root, cfg.Roots(conf.Routes, conf.Root))
+	for i := range roots {
+		if roots[i], err = filepath.Abs(roots[i]); err != nil {
+			return err
+		}
+	}
+
+	finders, err := findRoots(roots)
+	if err != nil {
+		return err
+	}
+
+	l := &layout{
+		root:      root,
+		routes:    conf.Routes,
+		defHost:   conf.DefHost,
+		defScheme: conf.DefScheme,
+		skipHost:  conf.SkipHost,
+	}
+
+	var relocations []*relocation
+
+	for _, repo := range reposOf(finders) {
+		// Repos cloned into a custom path (eg, with "path=" in a dump file) are where the user wants them.
+		if pinned, err := repo.Pinned(); err == nil && pinned {
+			continue
+		}
+
+		r := &relocation{repo: repo, src: repo.Path()}
+
+		remote, err := repo.Remote()
+		if err == nil && remote == "" {
+			continue
+		}
+
+		if err != nil {
+			r.err = err
+		} else {
+			r.dst, r.err = l.canonicalPath(remote)
+		}
+
+		if r.src != r.dst {
+			relocations = append(relocations, r)
+		}
+	}
+
+	if len(relocations) == 0 {
+		fmt.Println("All repositories are in place")
+
+		return nil
+	}
+
+	planRelocations(relocations)
+
+	// Emptied directories are removed up to the root the repo was in.
+	return relocate(relocations, !conf.Fix, func(src string) string { return rootOf(roots, src) })
+}
@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMisplaced(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "github.com", "grdl"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "github.com", "old-owner"), 0755))
+
+	inPlace := repoWithRemote(t, filepath.Join(root, "github.com", "grdl"), "in-place", "git@github.com:grdl/in-place.git")
+	renamed := repoWithRemote(t, filepath.Join(root, "github.com", "old-owner"), "old-name", "https://github.com/grdl/new-name")
+	noRemote := repoWithRemote(t, filepath.Join(root, "github.com", "grdl"), "no-remote", "")
+	pinned := repoWithRemote(t, filepath.Join(root, "github.com", "grdl"), "custom-path", "https://github.com/grdl/pinned")
+
+	pinnedRepo, err := git.Open(pinned)
+	require.NoError(t, err)
+	require.NoError(t, pinnedRepo.SetPinned())
+
+	conf := &ListCfg{
+		DefHost:   "github.com",
+		DefScheme: "ssh",
+		Misplaced: true,
+		Root:      root,
+	}
+
+	require.NoError(t, misplaced(conf))
+	assert.DirExists(t, renamed)
+
+	conf.Fix = true
+	require.NoError(t, misplaced(conf))
+
+	assert.DirExists(t, inPlace)
+	assert.DirExists(t, noRemote)
+	assert.DirExists(t, pinned, "repos cloned into a custom path are never moved")
+	assert.DirExists(t, filepath.Join(root, "github.com", "grdl", "new-name", ".git"))
+	assert.NoDirExists(t, filepath.Join(root, "github.com", "old-owner"))
+}
+
+func TestMisplacedTwoRoots(t *testing.T) {
+	t.Parallel()
+
+	personal := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+	require.NoError(t, os.MkdirAll(filepath.Join(personal, "github.com", "mycompany"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "github.com", "old-company"), 0755))
+
+	// A repo cloned before its route was added and a renamed repo under the root of the route.
+	api := repoWithRemote(t, filepath.Join(personal, "github.com", "mycompany"), "api", "git@github.com:mycompany/api.git")
+	renamed := repoWithRemote(t, filepath.Join(work, "github.com", "old-company"), "web", "git@github.com:mycompany/web.git")
+
+	conf := &ListCfg{
+		DefHost:   "github.com",
+		DefScheme: "ssh",
+		Misplaced: true,
+		Root:      personal,
+		Routes:    []cfg.Route{{Pattern: "github.com/mycompany", Root: work}},
+	}
+
+	require.NoError(t, misplaced(conf))
+	assert.DirExists(t, api)
+	assert.DirExists(t, renamed)
+
+	conf.Fix = true
+	require.NoError(t, misplaced(conf))
+
+	assert.DirExists(t, filepath.Join(work, "github.com", "mycompany", "api", ".git"))
+	assert.DirExists(t, filepath.Join(work, "github.com", "mycompany", "web", ".git"))
+
+	// Emptied directories are removed up to the root each repo was in, but not the roots themselves.
+	assert.NoDirExists(t, filepath.Join(personal, "github.com"))
+	assert.NoDirExists(t, filepath.Join(work, "github.com", "old-company"))
+	assert.DirExists(t, personal)
+}
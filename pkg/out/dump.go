@@ -1,18 +1,28 @@
 package out
 
 import (
+	"fmt"
 	"strings"
 )
 
 // DumpPrinter prints a list of repos in a dump file format.
-type DumpPrinter struct{}
+type DumpPrinter struct {
+	pathOverride func(repo Printable) string
+}
 
 // NewDumpPrinter creates a DumpPrinter.
-func NewDumpPrinter() *DumpPrinter {
-	return &DumpPrinter{}
+// pathOverride returns the path of a repo if it's different from the one where "git get" would clone it, or an empty string otherwise.
+// It can be nil, in which case paths are never printed.
+func NewDumpPrinter(pathOverride func(repo Printable) string) *DumpPrinter {
+	return &DumpPrinter{
+		pathOverride: pathOverride,
+	}
 }
 
 // Print generates a list of repos URLs. Each line contains a URL and, if applicable, a currently checked out branch name.
+// They are followed by "key=value" options describing how the repo is set up: its path (if it's not the default one),
+// depth of a shallow clone, directories of a sparse checkout, tags, remotes other than "origin" and the remote
+// tracked by the current branch (if it's not "origin").
 // It's a way to dump all repositories managed by git-get and is supposed to be consumed by `git get --dump`.
 func (p *DumpPrinter) Print(repos []Printable) string {
 	var str strings.Builder
@@ -25,8 +35,46 @@ func (p *DumpPrinter) Print(repos []Printable) string {
 			str.WriteString(" " + current)
 		}
 
+		for _, option := range p.options(r) {
+			str.WriteString(" " + option)
+		}
+
 		str.WriteString("\n")
 	}
 
 	return str.String()
 }
+
+func (p *DumpPrinter) options(r Printable) []string {
+	var options []string
+
+	if p.pathOverride != nil {
+		if path := p.pathOverride(r); path != "" {
+			options = append(options, "path="+path)
+		}
+	}
+
+	if depth := r.Depth(); depth > 0 {
+		options = append(options, fmt.Sprintf("depth=%d", depth))
+	}
+
+	if sparse := r.SparsePaths(); len(sparse) > 0 {
+		options = append(options, "sparse="+strings.Join(sparse, ","))
+	}
+
+	if tags := r.Tags(); len(tags) > 0 {
+		options = append(options, "tags="+strings.Join(tags, ","))
+	}
+
+	for _, name := range r.Remotes() {
+		if name != origin {
+			options = append(options, fmt.Sprintf("remote.%s=%s", name, r.RemoteURL(name)))
+		}
+	}
+
+	if tracked := r.TrackedRemote(); tracked != "" && tracked != origin && tracked != "." {
+		options = append(options, "track="+tracked)
+	}
+
+	return options
+}
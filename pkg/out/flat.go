@@ -7,11 +7,15 @@ import (
 )
 
 // FlatPrinter prints a list of repos in a flat format.
-type FlatPrinter struct{}
+type FlatPrinter struct {
+	opts Options
+}
 
 // NewFlatPrinter creates a FlatPrinter.
-func NewFlatPrinter() *FlatPrinter {
-	return &FlatPrinter{}
+func NewFlatPrinter(opts Options) *FlatPrinter {
+	return &FlatPrinter{
+		opts: opts,
+	}
 }
 
 // Print generates a flat list of repositories and their statuses - each repo in new line with full path.
@@ -19,7 +23,7 @@ func (p *FlatPrinter) Print(repos []Printable) string {
 	var str strings.Builder
 
 	for _, repo := range repos {
-		str.WriteString(strings.TrimSuffix(repo.Path(), string(os.PathSeparator)))
+		str.WriteString(highlight(repo, strings.TrimSuffix(repo.Path(), string(os.PathSeparator))))
 
 		if len(repo.Errors()) > 0 {
 			str.WriteString(" " + red("error") + "\n")
@@ -36,10 +40,24 @@ func (p *FlatPrinter) Print(repos []Printable) string {
 			worktree = fmt.Sprintf("[ %s ]", worktree)
 		}
 
-		if worktree == "" && current == "" {
+		state := repoState(repo)
+
+		if worktree == "" && current == "" && state == "" {
 			str.WriteString(" " + green("ok"))
 		} else {
-			str.WriteString(" " + strings.Join([]string{yellow(current), red(worktree)}, " "))
+			str.WriteString(" " + strings.Join([]string{yellow(current), red(worktree), state}, " "))
+		}
+
+		str.WriteString(withForgeStatus("", repo, repo.Current()))
+
+		if p.opts.DiskUsage {
+			str.WriteString(" " + diskUsage(repo))
+		}
+
+		indent := strings.Repeat(" ", len(repo.Path())-1)
+
+		if p.opts.LastCommit {
+			str.WriteString(fmt.Sprintf("\n%s %s", indent, lastCommit(repo)))
 		}
 
 		for _, branch := range repo.Branches() {
@@ -48,8 +66,11 @@ func (p *FlatPrinter) Print(repos []Printable) string {
 				status = green("ok")
 			}
 
-			indent := strings.Repeat(" ", len(repo.Path())-1)
-			str.WriteString(fmt.Sprintf("\n%s %s %s", indent, blue(branch), yellow(status)))
+			str.WriteString(withForgeStatus(fmt.Sprintf("\n%s %s %s", indent, blue(branch), yellow(status)), repo, branch))
+		}
+
+		for _, line := range remotes(repo) {
+			str.WriteString(fmt.Sprintf("\n%s %s", indent, line))
 		}
 
 		str.WriteString("\n")
@@ -4,10 +4,14 @@ package out
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/grdl/git-get/pkg/forge"
 )
 
 const (
-	head = "HEAD"
+	head   = "HEAD"
+	origin = "origin"
 )
 
 // Printable represents a repository which status can be printed.
@@ -16,11 +20,228 @@ type Printable interface {
 	Current() string
 	Branches() []string
 	BranchStatus(branch string) string
+	Upstream(branch string) string
 	WorkTreeStatus() string
+	Stashes() int
+	Operation() string
 	Remote() string
+	Remotes() []string
+	RemoteURL(name string) string
+	RemoteBranch(name string) string
+	RemoteStatus(name string) string
+	TrackedRemote() string
+	Depth() int
+	SparsePaths() []string
+	Tags() []string
+	LastCommitDate() time.Time
+	LastCommitAuthor() string
+	LastCommitSubject() string
+	LastActivity() time.Time
+	WorkTreeSize() int64
+	GitSize() int64
+	LFSSize() int64
 	Errors() []string
 }
 
+// Options controls which optional details are printed by the FlatPrinter and TreePrinter.
+type Options struct {
+	// DiskUsage prints the disk space taken by each repo and, in the tree output, the totals of each directory.
+	DiskUsage bool
+	// GroupByTag groups the repos by their tags in the tree output. Repos with multiple tags appear in each of their groups.
+	GroupByTag bool
+	// LastCommit prints date, author and subject of the last commit and the time since the last local activity.
+	LastCommit bool
+}
+
+// Highlighter is an optional interface implemented by Printables which should stand out in the output.
+// Eg, in watch mode repos which changed since the previous refresh are highlighted.
+type Highlighter interface {
+	Highlighted() bool
+}
+
+// highlight renders str in reverse video if the repo implements Highlighter and wants to be highlighted.
+func highlight(repo Printable, str string) string {
+	if h, ok := repo.(Highlighter); ok && h.Highlighted() {
+		return fmt.Sprintf("\033[7m%s\033[0m", str)
+	}
+
+	return str
+}
+
+// ForgeAnnotated is an optional interface implemented by Printables annotated with the state of their branches
+// on the forge hosting their remote: the latest pull request and the CI checks state.
+type ForgeAnnotated interface {
+	// PullRequest returns the number and state of the latest pull request from a branch, or zero if there's none.
+	PullRequest(branch string) (int, string)
+	// Checks returns the combined state of CI checks of a branch, or an empty string if there are none.
+	Checks(branch string) string
+}
+
+// forgeStatus returns the pull request and checks state of a branch, eg "PR #12 open checks failure",
+// or an empty string if the repo isn't annotated or there's nothing to show.
+func forgeStatus(repo Printable, branch string) string {
+	annotated, ok := repo.(ForgeAnnotated)
+	if !ok {
+		return ""
+	}
+
+	var res []string
+
+	if number, state := annotated.PullRequest(branch); number > 0 {
+		res = append(res, gray(fmt.Sprintf("PR #%d %s", number, state)))
+	}
+
+	switch checks := annotated.Checks(branch); checks {
+	case forge.ChecksSuccess:
+		res = append(res, green("checks "+checks))
+	case forge.ChecksPending:
+		res = append(res, yellow("checks "+checks))
+	case forge.ChecksFailure:
+		res = append(res, red("checks "+checks))
+	}
+
+	return strings.Join(res, " ")
+}
+
+// withForgeStatus appends the forge status of a branch to a line, if there's any.
+func withForgeStatus(line string, repo Printable, branch string) string {
+	if status := forgeStatus(repo, branch); status != "" {
+		return line + " " + status
+	}
+
+	return line
+}
+
+// repoState returns the operation in progress and the number of stashes (eg, "REBASING 3/7 2 stashes") or an empty string if there are none.
+func repoState(repo Printable) string {
+	var res []string
+
+	if op := repo.Operation(); op != "" {
+		res = append(res, red(op))
+	}
+
+	switch stashes := repo.Stashes(); {
+	case stashes == 1:
+		res = append(res, yellow("1 stash"))
+	case stashes > 1:
+		res = append(res, yellow(fmt.Sprintf("%d stashes", stashes)))
+	}
+
+	return strings.Join(res, " ")
+}
+
+// remotes returns a line for each remote describing how the current commit compares with the remote's default branch,
+// eg "remote upstream/main 2 behind". Repos with a single remote don't get any lines, their branches status is enough.
+func remotes(repo Printable) []string {
+	names := repo.Remotes()
+	if len(names) < 2 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(names))
+
+	for _, name := range names {
+		branch := repo.RemoteBranch(name)
+		if branch == "" {
+			branch = name
+		}
+
+		status := repo.RemoteStatus(name)
+		if status == "" {
+			status = green("ok")
+		} else {
+			status = yellow(status)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s %s", gray("remote"), blue(branch), status))
+	}
+
+	return lines
+}
+
+// lastCommit returns a description of the last commit and the last activity, eg "3 months ago by Jane: Fix typo, active 2 days ago".
+func lastCommit(repo Printable) string {
+	if repo.LastCommitDate().IsZero() {
+		return gray("no commits")
+	}
+
+	res := fmt.Sprintf("%s by %s: %s", ago(repo.LastCommitDate()), repo.LastCommitAuthor(), repo.LastCommitSubject())
+
+	if activity := repo.LastActivity(); activity.After(repo.LastCommitDate()) {
+		res += ", active " + ago(activity)
+	}
+
+	return gray(res)
+}
+
+// diskUsage returns the total disk space taken by the repo followed by its breakdown, eg "1.2 GiB (worktree 800 MiB, .git 400 MiB)".
+// LFS objects are mentioned only if there are any.
+func diskUsage(repo Printable) string {
+	total := repo.WorkTreeSize() + repo.GitSize() + repo.LFSSize()
+	parts := []string{"worktree " + formatSize(repo.WorkTreeSize()), ".git " + formatSize(repo.GitSize())}
+
+	if repo.LFSSize() > 0 {
+		parts = append(parts, "lfs "+formatSize(repo.LFSSize()))
+	}
+
+	return gray(fmt.Sprintf("%s (%s)", formatSize(total), strings.Join(parts, ", ")))
+}
+
+// formatSize returns a human readable size in binary units, eg "512 B" or "1.5 MiB".
+func formatSize(bytes int64) string {
+	const unit = 1024
+
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// ago returns a rough, human readable time elapsed since t, eg "5 minutes ago" or "2 years ago".
+func ago(t time.Time) string {
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+
+	since := time.Since(t)
+
+	switch {
+	case since < time.Minute:
+		return "just now"
+	case since < time.Hour:
+		return Plural(int(since/time.Minute), "minute") + " ago"
+	case since < day:
+		return Plural(int(since/time.Hour), "hour") + " ago"
+	case since < month:
+		return Plural(int(since/day), "day") + " ago"
+	case since < year:
+		return Plural(int(since/month), "month") + " ago"
+	default:
+		return Plural(int(since/year), "year") + " ago"
+	}
+}
+
+// Plural returns a count with a unit, eg "1 stash" or "2 stashes".
+func Plural(n int, unit string) string {
+	switch {
+	case n == 1:
+		return "1 " + unit
+	case strings.HasSuffix(unit, "sh"):
+		return fmt.Sprintf("%d %ses", n, unit)
+	default:
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+}
+
 // Errors returns a printable list of errors from the slice of Printables or an empty string if there are no errors.
 // It's meant to be appended at the end of Print() result.
 func Errors(repos []Printable) string {
@@ -57,3 +278,7 @@ func blue(str string) string {
 func yellow(str string) string {
 	return fmt.Sprintf("\033[1;33m%s\033[0m", str)
 }
+
+func gray(str string) string {
+	return fmt.Sprintf("\033[0;90m%s\033[0m", str)
+}
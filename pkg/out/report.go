@@ -0,0 +1,103 @@
+package out
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Reportable represents a repository health report which can be printed.
+type Reportable interface {
+	Path() string
+	Gone() []string
+	Merged() []string
+	Stale() []string
+	Unpushed() []string
+	UnpushedCount(branch string) int
+	Stashes() int
+	Errors() []string
+}
+
+// ReportPrinter prints repositories health reports aggregated across all repos.
+type ReportPrinter struct{}
+
+// NewReportPrinter creates a ReportPrinter.
+func NewReportPrinter() *ReportPrinter {
+	return &ReportPrinter{}
+}
+
+// reportCategory counts the findings of a single category across all repos.
+type reportCategory struct {
+	name  string
+	unit  string
+	count int
+	repos int
+}
+
+// Print generates a list of repos needing attention with their findings, followed by counts per category.
+// Repos without any findings are skipped.
+func (p *ReportPrinter) Print(repos []Reportable) string {
+	var str strings.Builder
+
+	categories := []*reportCategory{
+		{name: "gone upstream", unit: "branches"},
+		{name: "merged", unit: "branches"},
+		{name: "stale", unit: "branches"},
+		{name: "unpushed", unit: "branches"},
+		{name: "stashes", unit: "stashes"},
+	}
+	errors := []string{}
+
+	for _, repo := range repos {
+		errors = append(errors, repo.Errors()...)
+
+		unpushed := make([]string, 0, len(repo.Unpushed()))
+		for _, branch := range repo.Unpushed() {
+			unpushed = append(unpushed, fmt.Sprintf("%s (%d)", branch, repo.UnpushedCount(branch)))
+		}
+
+		var stashes []string
+		if repo.Stashes() > 0 {
+			stashes = []string{strconv.Itoa(repo.Stashes())}
+		}
+
+		findings := [][]string{repo.Gone(), repo.Merged(), repo.Stale(), unpushed, stashes}
+		counts := []int{len(repo.Gone()), len(repo.Merged()), len(repo.Stale()), len(unpushed), repo.Stashes()}
+
+		var lines []string
+
+		for i, found := range findings {
+			if len(found) == 0 {
+				continue
+			}
+
+			categories[i].repos++
+			categories[i].count += counts[i]
+
+			lines = append(lines, fmt.Sprintf("    %s: %s", yellow(categories[i].name), strings.Join(found, ", ")))
+		}
+
+		if len(lines) == 0 {
+			continue
+		}
+
+		str.WriteString(blue(repo.Path()) + "\n" + strings.Join(lines, "\n") + "\n")
+	}
+
+	if str.Len() == 0 && len(errors) == 0 {
+		str.WriteString(green("All repositories are ok") + "\n")
+	}
+
+	str.WriteString("\nSummary:\n")
+
+	for _, c := range categories {
+		str.WriteString(fmt.Sprintf("    %-14s %d %s in %d repos\n", c.name+":", c.count, c.unit, c.repos))
+	}
+
+	if len(errors) > 0 {
+		str.WriteString(red("\nOops, errors happened when loading repository report:\n"))
+		str.WriteString(strings.Join(errors, "\n"))
+	}
+
+	return str.String()
+}
@@ -2,7 +2,9 @@ package out
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/xlab/treeprint"
@@ -10,26 +12,72 @@ import (
 
 // TreePrinter prints list of repos in a directory tree format.
 type TreePrinter struct {
+	opts Options
 }
 
 // NewTreePrinter creates a TreePrinter.
-func NewTreePrinter() *TreePrinter {
-	return &TreePrinter{}
+func NewTreePrinter(opts Options) *TreePrinter {
+	return &TreePrinter{
+		opts: opts,
+	}
 }
 
 // Print generates a tree view of repos and their statuses.
 func (p *TreePrinter) Print(root string, repos []Printable) string {
+	return p.PrintRoots([]string{root}, repos)
+}
+
+// PrintRoots generates a tree view of repos and their statuses with a separate tree for each root.
+// Each repo goes into the tree of the innermost root containing it. Roots without repos are omitted.
+func (p *TreePrinter) PrintRoots(roots []string, repos []Printable) string {
 	if len(repos) == 0 {
-		return "There are no git repos under " + root
+		return "There are no git repos under " + strings.Join(roots, ", ")
 	}
 
-	tree := buildTree(root, repos)
-	tp := treeprint.New()
-	tp.SetValue(root)
+	groups := groupByRoot(roots, repos)
+	trees := make([]string, 0, len(roots))
 
-	p.printTree(tree, tp)
+	for _, root := range roots {
+		if len(groups[root]) == 0 {
+			continue
+		}
 
-	return tp.String() + Errors(repos)
+		tree := buildTree(root, groups[root])
+		if p.opts.GroupByTag {
+			tree = buildTagTree(root, groups[root])
+		}
+
+		tp := treeprint.New()
+		tp.SetValue(p.nodeName(tree))
+
+		p.printTree(tree, tp)
+
+		trees = append(trees, tp.String())
+	}
+
+	return strings.Join(trees, "\n") + Errors(repos)
+}
+
+// groupByRoot assigns each repo to the innermost root containing it, keeping the order of repos.
+// Repos outside of all roots are assigned to the first root.
+func groupByRoot(roots []string, repos []Printable) map[string][]Printable {
+	groups := make(map[string][]Printable, len(roots))
+
+	for _, repo := range repos {
+		best := roots[0]
+		longest := -1
+
+		for _, root := range roots {
+			inside := strings.HasPrefix(repo.Path(), strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+			if inside && len(root) > longest {
+				best, longest = root, len(root)
+			}
+		}
+
+		groups[best] = append(groups[best], repo)
+	}
+
+	return groups
 }
 
 // Node represents a path fragment in repos tree.
@@ -85,59 +133,126 @@ func (n *Node) GetChild(val string) *Node {
 // buildTree builds a directory tree of paths to repositories.
 // Each node represents a directory in the repo path.
 // Each leaf (final node) contains a pointer to the repo.
+// Siblings keep the order in which they first appear in repos, so a sorted slice of repos gives sorted siblings.
 func buildTree(root string, repos []Printable) *Node {
 	tree := Root(root)
 
 	for _, repo := range repos {
-		path := strings.TrimPrefix(repo.Path(), root)
-		path = strings.Trim(path, string(filepath.Separator))
-		subs := strings.Split(path, string(filepath.Separator))
-
-		// For each path fragment, start at the root of the tree
-		// and check if the fragment exist among the children of the node.
-		// If not, add it to node's children and move to next fragment.
-		// If it does, just move to the next fragment.
-		node := tree
-		for idx, sub := range subs {
-			child := node.GetChild(sub)
-			if child == nil {
-				node = node.Add(sub)
-
-				// If that's the last fragment, it's a tree leaf and needs a *Repo attached.
-				if idx == len(subs)-1 {
-					node.repo = repo
-				}
-
-				continue
-			}
+		tree.addRepo(root, repo)
+	}
+
+	return tree
+}
 
-			node = child
+// buildTagTree builds a tree with a node for each tag (sorted by name) containing a directory tree of repos with that tag.
+// Repos without tags are grouped under the "(untagged)" node, which goes last.
+func buildTagTree(root string, repos []Printable) *Node {
+	const untagged = "(untagged)"
+
+	groups := make(map[string][]Printable)
+
+	for _, repo := range repos {
+		if len(repo.Tags()) == 0 {
+			groups[untagged] = append(groups[untagged], repo)
+		}
+
+		for _, tag := range repo.Tags() {
+			groups[tag] = append(groups[tag], repo)
+		}
+	}
+
+	tags := slices.Sorted(maps.Keys(groups))
+	if i := slices.Index(tags, untagged); i >= 0 {
+		tags = append(slices.Delete(tags, i, i+1), untagged)
+	}
+
+	tree := Root(root)
+
+	for _, tag := range tags {
+		node := tree.Add(tag)
+		for _, repo := range groups[tag] {
+			node.addRepo(root, repo)
 		}
 	}
 
 	return tree
 }
 
+// addRepo adds nodes for each directory in the repo path (relative to root) below this node, unless they already exist.
+func (n *Node) addRepo(root string, repo Printable) {
+	path := strings.TrimPrefix(repo.Path(), root)
+	path = strings.Trim(path, string(filepath.Separator))
+	subs := strings.Split(path, string(filepath.Separator))
+
+	// For each path fragment, start at this node and check if the fragment exist among the children of the node.
+	// If not, add it to node's children and move to next fragment.
+	// If it does, just move to the next fragment.
+	node := n
+	for idx, sub := range subs {
+		child := node.GetChild(sub)
+		if child == nil {
+			node = node.Add(sub)
+
+			// If that's the last fragment, it's a tree leaf and needs a *Repo attached.
+			if idx == len(subs)-1 {
+				node.repo = repo
+			}
+
+			continue
+		}
+
+		node = child
+	}
+}
+
 // printTree renders the repo tree by recursively traversing the tree nodes.
 // If a node doesn't have any children, it's a leaf node containing the repo status.
 func (p *TreePrinter) printTree(node *Node, tree treeprint.Tree) {
 	if node.children == nil {
-		tree.SetValue(printLeaf(node))
+		tree.SetValue(p.printLeaf(node))
 	}
 
 	for _, child := range node.children {
-		branch := tree.AddBranch(child.val)
+		name := child.val
+		if child.children != nil {
+			name = p.nodeName(child)
+		}
+
+		branch := tree.AddBranch(name)
 		p.printTree(child, branch)
 	}
 }
 
-func printLeaf(node *Node) string {
+// nodeName returns the name of a directory node, followed by the total size of repos inside it if disk usage is printed.
+func (p *TreePrinter) nodeName(node *Node) string {
+	if !p.opts.DiskUsage {
+		return node.val
+	}
+
+	return fmt.Sprintf("%s %s", node.val, gray(formatSize(node.size())))
+}
+
+// size returns the total disk usage of all repos in the node and its children.
+func (n *Node) size() int64 {
+	if n.repo != nil {
+		return n.repo.WorkTreeSize() + n.repo.GitSize() + n.repo.LFSSize()
+	}
+
+	var size int64
+	for _, child := range n.children {
+		size += child.size()
+	}
+
+	return size
+}
+
+func (p *TreePrinter) printLeaf(node *Node) string {
 	repo := node.repo
 
 	// If any errors happened during status loading, don't print the status but "error" instead.
 	// Actual error messages are printed in bulk below the tree.
 	if len(repo.Errors()) > 0 {
-		return fmt.Sprintf("%s %s", node.val, red("error"))
+		return fmt.Sprintf("%s %s", highlight(repo, node.val), red("error"))
 	}
 
 	current := repo.BranchStatus(repo.Current())
@@ -149,10 +264,23 @@ func printLeaf(node *Node) string {
 
 	var str strings.Builder
 
-	if worktree == "" && current == "" {
-		str.WriteString(fmt.Sprintf("%s %s %s", node.val, blue(repo.Current()), green("ok")))
+	name := highlight(repo, node.val)
+	state := repoState(repo)
+
+	if worktree == "" && current == "" && state == "" {
+		str.WriteString(fmt.Sprintf("%s %s %s", name, blue(repo.Current()), green("ok")))
 	} else {
-		str.WriteString(fmt.Sprintf("%s %s %s", node.val, blue(repo.Current()), strings.Join([]string{yellow(current), red(worktree)}, " ")))
+		str.WriteString(fmt.Sprintf("%s %s %s", name, blue(repo.Current()), strings.Join([]string{yellow(current), red(worktree), state}, " ")))
+	}
+
+	str.WriteString(withForgeStatus("", repo, repo.Current()))
+
+	if p.opts.DiskUsage {
+		str.WriteString(" " + diskUsage(repo))
+	}
+
+	if p.opts.LastCommit {
+		str.WriteString(fmt.Sprintf("\n%s%s", indentation(node), lastCommit(repo)))
 	}
 
 	for _, branch := range repo.Branches() {
@@ -161,7 +289,11 @@ func printLeaf(node *Node) string {
 			status = green("ok")
 		}
 
-		str.WriteString(fmt.Sprintf("\n%s%s %s", indentation(node), blue(branch), yellow(status)))
+		str.WriteString(withForgeStatus(fmt.Sprintf("\n%s%s %s", indentation(node), blue(branch), yellow(status)), repo, branch))
+	}
+
+	for _, line := range remotes(repo) {
+		str.WriteString(fmt.Sprintf("\n%s%s", indentation(node), line))
 	}
 
 	return str.String()
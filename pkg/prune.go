@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/out"
+)
+
+// PruneCfg provides configuration for the PruneBranches command.
+type PruneCfg struct {
+	DryRun bool
+	Root   string
+	Routes []cfg.Route // Roots other than Root for repos matching their patterns. Their repos are pruned too.
+	Tags   []string
+}
+
+// prunedBranch describes what happened (or would happen in a dry run) to a branch selected for pruning.
+type prunedBranch struct {
+	name    string
+	reason  string // Why the branch was selected: "merged" or "gone".
+	skipped string // Why the branch was kept despite being selected, empty if it was deleted.
+}
+
+// PruneBranches executes the "git get prune-branches" command.
+// It deletes local branches merged into the default branch or whose upstream is gone, in all repos under the root
+// and the roots of routes.
+// The current branch and branches with commits not existing on any remote are never deleted.
+func PruneBranches(conf *PruneCfg) error {
+	finders, err := findRoots(allRoots(conf.Root, cfg.Roots(conf.Routes, conf.Root)))
+	if err != nil {
+		return err
+	}
+
+	repos, err := reposWithTags(reposOf(finders), conf.Tags)
+	if err != nil {
+		return err
+	}
+
+	verb := "deleted"
+	if conf.DryRun {
+		verb = "would delete"
+	}
+
+	var errs []string
+
+	for _, repo := range repos {
+		pruned, err := pruneRepo(repo, conf.DryRun)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		if len(pruned) == 0 {
+			continue
+		}
+
+		fmt.Println(repo.Path())
+
+		for _, b := range pruned {
+			if b.skipped != "" {
+				fmt.Printf("    skipped %s (%s): %s\n", b.name, b.reason, b.skipped)
+			} else {
+				fmt.Printf("    %s %s (%s)\n", verb, b.name, b.reason)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed pruning branches:\n%s", strings.Join(errs, "\n")) //nolint:err113 // Wraps errors from multiple repos.
+	}
+
+	return nil
+}
+
+// pruneRepo deletes merged and gone branches of a single repo.
+func pruneRepo(repo *git.Repo, dryRun bool) ([]prunedBranch, error) {
+	// An empty repo doesn't have any branches to prune.
+	if dates, err := repo.LastCommitDates(); err != nil || len(dates) == 0 {
+		return nil, err
+	}
+
+	current, err := repo.CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := repo.DefaultBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := repo.MergedBranches(base)
+	if err != nil {
+		return nil, err
+	}
+
+	gone, err := repo.GoneBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]string)
+	for _, branch := range gone {
+		candidates[branch] = "gone"
+	}
+
+	for _, branch := range merged {
+		candidates[branch] = "merged"
+	}
+
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var pruned []prunedBranch
+
+	for _, name := range names {
+		branch := prunedBranch{name: name, reason: candidates[name]}
+
+		switch {
+		case name == current:
+			branch.skipped = "currently checked out"
+		case name == strings.TrimPrefix(base, "origin/"):
+			branch.skipped = "default branch"
+		default:
+			unpushed, err := repo.Unpushed(name)
+			if err != nil {
+				return pruned, err
+			}
+
+			if unpushed > 0 {
+				branch.skipped = out.Plural(unpushed, "commit") + " not pushed to any remote"
+			}
+		}
+
+		if branch.skipped == "" && !dryRun {
+			if err := repo.DeleteBranch(name); err != nil {
+				return pruned, err
+			}
+		}
+
+		pruned = append(pruned, branch)
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneRepo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		repoMaker    func(*testing.T) *test.Repo
+		dryRun       bool
+		wantPruned   []prunedBranch
+		wantBranches []string
+	}{
+		{
+			name:         "empty",
+			repoMaker:    test.RepoEmpty,
+			wantPruned:   nil,
+			wantBranches: []string{""},
+		},
+		{
+			name:      "gone and merged",
+			repoMaker: test.RepoWithGoneUpstream,
+			wantPruned: []prunedBranch{
+				{name: "feature/branch", reason: "merged"},
+			},
+			wantBranches: []string{"main"},
+		},
+		{
+			name:      "dry run",
+			repoMaker: test.RepoWithGoneUpstream,
+			dryRun:    true,
+			wantPruned: []prunedBranch{
+				{name: "feature/branch", reason: "merged"},
+			},
+			wantBranches: []string{"feature/branch", "main"},
+		},
+		{
+			name:      "merged but not pushed",
+			repoMaker: test.RepoWithMergedBranch,
+			wantPruned: []prunedBranch{
+				{name: "feature/merged", reason: "merged", skipped: "1 commit not pushed to any remote"},
+			},
+			wantBranches: []string{"feature/merged", "feature/unmerged", "main"},
+		},
+		{
+			name:         "current branch ahead",
+			repoMaker:    test.RepoWithBranchAhead,
+			wantPruned:   nil,
+			wantBranches: []string{"feature/branch", "main"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo, err := git.Open(test.repoMaker(t).Path())
+			require.NoError(t, err)
+
+			got, err := pruneRepo(repo, test.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantPruned, got)
+
+			branches, err := repo.Branches()
+			require.NoError(t, err)
+			assert.Equal(t, test.wantBranches, branches)
+		})
+	}
+}
+
+func TestPruneBranchesTwoRoots(t *testing.T) {
+	t.Parallel()
+
+	personal := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+
+	dotfiles := filepath.Join(personal, "dotfiles")
+	api := filepath.Join(work, "api")
+	require.NoError(t, os.Rename(test.RepoWithGoneUpstream(t).Path(), dotfiles))
+	require.NoError(t, os.Rename(test.RepoWithGoneUpstream(t).Path(), api))
+
+	require.NoError(t, PruneBranches(&PruneCfg{Root: personal, Routes: []cfg.Route{{Pattern: "github.com/mycompany", Root: work}}}))
+
+	for _, path := range []string{dotfiles, api} {
+		repo, err := git.Open(path)
+		require.NoError(t, err)
+
+		branches, err := repo.Branches()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main"}, branches, path)
+	}
+}
@@ -0,0 +1,223 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/forge"
+	"github.com/grdl/git-get/pkg/out"
+)
+
+// How long the pull requests and checks loaded from forges are cached.
+const forgeCacheTTL = 5 * time.Minute
+
+// Number of repos whose pull requests and checks are loaded concurrently.
+const forgeWorkers = 8
+
+// forgeProviders creates (and remembers) the forge providers for the hosts of repos' remotes.
+// Hosts of public forges are recognized automatically. Other hosts need the forge set in gitconfig, eg:
+//
+//	[gitget "gitlab.example.com"]
+//	    forge = gitlab
+//	    token = <access token>
+//
+// The token falls back to the GITHUB_TOKEN, GITLAB_TOKEN or GITEA_TOKEN env var. The API URL can be overridden with "api-url".
+type forgeProviders struct {
+	gitconfig cfg.Gitconfig
+	cacheDir  string // Empty if responses shouldn't be cached.
+	mu        sync.Mutex
+	providers map[string]forge.Provider // Nil value for hosts without a supported forge.
+}
+
+func newForgeProviders(gitconfig cfg.Gitconfig) *forgeProviders {
+	providers := &forgeProviders{
+		gitconfig: gitconfig,
+		providers: make(map[string]forge.Provider),
+	}
+
+	if dir, err := os.UserCacheDir(); err == nil {
+		providers.cacheDir = filepath.Join(dir, "git-get", "forge")
+	}
+
+	return providers
+}
+
+// forHost returns the provider of the forge on the host or nil if the host isn't a supported forge.
+func (f *forgeProviders) forHost(host string) (forge.Provider, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if provider, ok := f.providers[host]; ok {
+		return provider, nil
+	}
+
+	key := func(name string) string {
+		return fmt.Sprintf("%s.%s.%s", cfg.GitgetPrefix, host, name)
+	}
+
+	name := f.gitconfig.Get(key(cfg.KeyForge))
+	if name == "" {
+		name = forge.ForHost(host)
+	}
+
+	if name == "" {
+		f.providers[host] = nil
+
+		return nil, nil
+	}
+
+	apiURL := f.gitconfig.Get(key(cfg.KeyAPIURL))
+	if apiURL == "" {
+		apiURL = forge.APIURL(name, host)
+	}
+
+	token := f.gitconfig.Get(key(cfg.KeyToken))
+	if token == "" {
+		token = os.Getenv(tokenEnvVars[name])
+	}
+
+	provider, err := forge.New(name, forge.Config{BaseURL: apiURL, Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("invalid forge of %s: %w", host, err)
+	}
+
+	if f.cacheDir != "" {
+		provider = forge.Cached(provider, filepath.Join(f.cacheDir, host), forgeCacheTTL)
+	}
+
+	f.providers[host] = provider
+
+	return provider, nil
+}
+
+// forgeAnnotated wraps a Printable with the pull requests and checks of its branches.
+type forgeAnnotated struct {
+	out.Printable
+	branches map[string]forge.BranchInfo
+}
+
+// PullRequest implements the out.ForgeAnnotated interface.
+func (a forgeAnnotated) PullRequest(branch string) (int, string) {
+	if pr := a.branches[branch].PullRequest; pr != nil {
+		return pr.Number, pr.State
+	}
+
+	return 0, ""
+}
+
+// Checks implements the out.ForgeAnnotated interface.
+func (a forgeAnnotated) Checks(branch string) string {
+	return a.branches[branch].Checks
+}
+
+// withForgeInfo annotates the repos whose remote is hosted on a supported forge with the pull requests and checks of their branches.
+// Repos whose info can't be loaded are printed without it and a warning is printed to stderr.
+func withForgeInfo(printables []out.Printable, providerFor func(host string) (forge.Provider, error)) []out.Printable {
+	annotated := make([]out.Printable, len(printables))
+	copy(annotated, printables)
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, forgeWorkers)
+
+	for i, repo := range printables {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			branches, err := loadForgeInfo(repo, providerFor)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed loading pull requests of %s: %v\n", repo.Path(), err)
+			}
+
+			if len(branches) > 0 {
+				annotated[i] = forgeAnnotated{Printable: repo, branches: branches}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return annotated
+}
+
+// loadForgeInfo loads the pull requests and checks of all branches of the repo. It returns nil if the repo isn't hosted on a supported forge.
+func loadForgeInfo(repo out.Printable, providerFor func(host string) (forge.Provider, error)) (map[string]forge.BranchInfo, error) {
+	if len(repo.Errors()) > 0 || repo.Remote() == "" {
+		return nil, nil
+	}
+
+	url, err := ParseURL(repo.Remote(), "", "")
+	if err != nil || url.Hostname() == "" {
+		return nil, nil //nolint:nilerr // Remotes which aren't URLs of a forge are simply not annotated.
+	}
+
+	provider, err := providerFor(url.Hostname())
+	if provider == nil || err != nil {
+		return nil, err
+	}
+
+	path := URLToPath(*url, true)
+	branches := make(map[string]forge.BranchInfo)
+
+	for _, branch := range forgeBranches(repo) {
+		// Pull requests are looked up by the name of the branch on the remote, which can differ from the local one.
+		// Only the repo of the remote is queried, so pull requests opened from a fork against its upstream aren't found.
+		info, err := provider.BranchInfo(context.Background(), path, remoteBranchName(repo, repo.Upstream(branch)))
+		if err != nil {
+			return branches, err
+		}
+
+		branches[branch] = info
+	}
+
+	return branches, nil
+}
+
+// forgeBranches returns the current branch followed by other local branches which track a remote branch.
+// A detached HEAD and branches without an upstream can't have pull requests so they're skipped.
+func forgeBranches(repo out.Printable) []string {
+	var branches []string
+
+	for _, branch := range append([]string{repo.Current()}, repo.Branches()...) {
+		if branch == "" || branch == "HEAD" || repo.Upstream(branch) == "" || slices.Contains(branches, branch) {
+			continue
+		}
+
+		branches = append(branches, branch)
+	}
+
+	return branches
+}
+
+// remoteBranchName strips the remote name from an upstream branch, eg "origin/feature/x" becomes "feature/x".
+// Remote names can contain slashes so the longest matching remote name is stripped.
+func remoteBranchName(repo out.Printable, upstream string) string {
+	remote := ""
+
+	for _, name := range repo.Remotes() {
+		if strings.HasPrefix(upstream, name+"/") && len(name) > len(remote) {
+			remote = name
+		}
+	}
+
+	if remote == "" {
+		// Remotes aren't known, eg in statuses loaded by an older daemon. Assume a remote name without slashes.
+		_, branch, _ := strings.Cut(upstream, "/")
+
+		return branch
+	}
+
+	return strings.TrimPrefix(upstream, remote+"/")
+}
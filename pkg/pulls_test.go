@@ -0,0 +1,142 @@
+package pkg
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/forge"
+	"github.com/grdl/git-get/pkg/out"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeForge returns the branch infos by "repo:branch" keys and records the queried keys.
+type fakeForge struct {
+	mu      sync.Mutex
+	infos   map[string]forge.BranchInfo
+	queried []string
+}
+
+func (f *fakeForge) ListRepos(context.Context, string) ([]forge.Repo, error) {
+	return nil, nil
+}
+
+func (f *fakeForge) BranchInfo(_ context.Context, repo string, branch string) (forge.BranchInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queried = append(f.queried, repo+":"+branch)
+
+	return f.infos[repo+":"+branch], nil
+}
+
+// fakeGitconfig returns values from a map.
+type fakeGitconfig map[string]string
+
+func (c fakeGitconfig) Get(key string) string {
+	return c[key]
+}
+
+func (c fakeGitconfig) Values() map[string]string {
+	return c
+}
+
+func TestWithForgeInfo(t *testing.T) {
+	t.Parallel()
+
+	statuses := testStatuses(t, `[
+		{"path": "/repos/github.com/grdl/git-get", "current": "main", "branches": {"main": "", "feature": "1 ahead", "local": "no upstream"},
+		 "upstreams": {"main": "origin/main", "feature": "my/fork/feature-renamed"}, "remote": "git@github.com:grdl/git-get.git",
+		 "remotes": [{"name": "origin"}, {"name": "my/fork"}]},
+		{"path": "/repos/github.com/grdl/detached", "current": "HEAD", "branches": {"main": ""}, "upstreams": {"main": "origin/main"},
+		 "remote": "git@github.com:grdl/detached.git"},
+		{"path": "/repos/example.com/other", "current": "main", "branches": {"main": ""}, "remote": "https://example.com/other.git"},
+		{"path": "/repos/local", "current": "main", "branches": {"main": ""}}
+	]`)
+
+	fake := &fakeForge{infos: map[string]forge.BranchInfo{
+		"grdl/git-get:main":            {Checks: forge.ChecksSuccess},
+		"grdl/git-get:feature-renamed": {PullRequest: &forge.PullRequest{Number: 12, State: forge.StateOpen}, Checks: forge.ChecksFailure},
+	}}
+
+	var (
+		mu    sync.Mutex
+		hosts []string
+	)
+
+	printables := withForgeInfo(toPrintables(statuses), func(host string) (forge.Provider, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		hosts = append(hosts, host)
+
+		if host == "github.com" {
+			return fake, nil
+		}
+
+		return nil, nil
+	})
+
+	assert.ElementsMatch(t, []string{"github.com", "github.com", "example.com"}, hosts, "repos without a remote URL are skipped")
+	assert.ElementsMatch(t, []string{"grdl/git-get:main", "grdl/git-get:feature-renamed", "grdl/detached:main"}, fake.queried,
+		"branches are queried once, by their upstream name, and detached HEAD and branches without upstream are skipped")
+
+	annotated, ok := printables[0].(out.ForgeAnnotated)
+	require.True(t, ok)
+
+	number, state := annotated.PullRequest("feature")
+	assert.Equal(t, 12, number)
+	assert.Equal(t, forge.StateOpen, state)
+	assert.Equal(t, forge.ChecksFailure, annotated.Checks("feature"))
+	assert.Equal(t, forge.ChecksSuccess, annotated.Checks("main"))
+
+	number, _ = annotated.PullRequest("main")
+	assert.Zero(t, number)
+
+	_, ok = printables[2].(out.ForgeAnnotated)
+	assert.False(t, ok, "repos on unsupported hosts are not annotated")
+
+	tree := out.NewFlatPrinter(out.Options{}).Print(printables)
+	assert.Contains(t, tree, "PR #12 open")
+	assert.Equal(t, 1, strings.Count(tree, "checks failure"))
+}
+
+func TestForgeProviders(t *testing.T) {
+	t.Parallel()
+
+	providers := newForgeProviders(fakeGitconfig{
+		"gitget.git.example.com.forge": "gitlab",
+		"gitget.bad.example.com.forge": "bitbucket",
+	})
+	providers.cacheDir = ""
+
+	tests := []struct {
+		host    string
+		want    bool
+		wantErr bool
+	}{
+		{host: "github.com", want: true},
+		{host: "codeberg.org", want: true},
+		{host: "git.example.com", want: true},
+		{host: "example.com", want: false},
+		{host: "bad.example.com", wantErr: true},
+	}
+
+	for _, test := range tests {
+		provider, err := providers.forHost(test.host)
+		if test.wantErr {
+			assert.ErrorIs(t, err, forge.ErrUnknownForge, test.host)
+
+			continue
+		}
+
+		require.NoError(t, err, test.host)
+		assert.Equal(t, test.want, provider != nil, test.host)
+	}
+
+	first, _ := providers.forHost("github.com")
+	second, _ := providers.forHost("github.com")
+	assert.Same(t, first, second, "providers are reused")
+}
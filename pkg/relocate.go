@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+)
+
+var (
+	errNoRemote      = errors.New("repository has no remote")
+	errTargetExists  = errors.New("target path already exists")
+	errTargetTaken   = errors.New("target path is also the target of")
+	errTargetNesting = errors.New("target path overlaps with the repository path")
+)
+
+// layout computes the canonical paths of repos under the root (or the roots of routes), the same way "git get" does when cloning them.
+type layout struct {
+	root      string
+	routes    []cfg.Route
+	defHost   string
+	defScheme string
+	skipHost  bool
+}
+
+// canonicalPath returns the path where a repo with given remote URL should be located.
+func (l *layout) canonicalPath(remote string) (string, error) {
+	if remote == "" {
+		return "", errNoRemote
+	}
+
+	url, err := ParseURL(remote, l.defHost, l.defScheme)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(routeRoot(l.root, l.routes, url), URLToPath(*url, l.skipHost)), nil
+}
+
+// relocation describes a move of a repo into its canonical path.
+type relocation struct {
+	repo *git.Repo
+	src  string
+	dst  string
+	err  error // Why the repo can't be moved, nil if it can.
+}
+
+// planRelocations checks if the repos can be moved into their targets and sets the relocation error if they can't.
+// A repo can't be moved when its target already exists, is the target of another repo or is nested inside the repo (or vice versa).
+func planRelocations(relocations []*relocation) {
+	targets := make(map[string]string)
+
+	for _, r := range relocations {
+		if r.err != nil {
+			continue
+		}
+
+		switch {
+		case targets[r.dst] != "":
+			r.err = fmt.Errorf("%w %s", errTargetTaken, targets[r.dst])
+		case isInside(r.src, r.dst) || isInside(r.dst, r.src):
+			r.err = errTargetNesting
+		default:
+			if _, err := os.Lstat(r.dst); err == nil {
+				r.err = errTargetExists
+			}
+		}
+
+		targets[r.dst] = r.src
+	}
+}
+
+// relocate moves the repos into their targets, or only prints what would be moved in a dry run.
+// The source directories left empty are removed too, but only the ones inside the directory returned by stop.
+// It returns the errors of repos which couldn't be moved.
+func relocate(relocations []*relocation, dryRun bool, stop func(src string) string) error {
+	var errs []error
+
+	for _, r := range relocations {
+		if r.err == nil && !dryRun {
+			r.err = r.repo.Move(r.dst, stop(r.src))
+		}
+
+		switch {
+		case r.err != nil:
+			errs = append(errs, fmt.Errorf("skipped %s: %w", r.src, r.err))
+		case dryRun:
+			fmt.Printf("would move %s to %s\n", r.src, r.dst)
+		default:
+			fmt.Printf("moved %s to %s\n", r.src, r.dst)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed relocating repositories:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,437 @@
+package pkg
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/out"
+)
+
+var (
+	ErrUnsafeRemove         = errors.New("refusing to remove repository")
+	ErrInvalidArchiveFormat = errors.New("invalid archive format")
+	errNotARepo             = errors.New("not a git repository")
+	errRemoveRoot           = errors.New("refusing to remove the repos root")
+	errOutsideRoots         = errors.New("refusing to remove a repository outside of the repos root")
+	errInvalidArchiveDir    = errors.New("invalid archive directory")
+)
+
+// RmCfg provides configuration for the Rm command.
+type RmCfg struct {
+	Archive       string // Directory where the repos are archived before removal. Archiving is skipped if empty.
+	ArchiveFormat string
+	Force         bool
+	Paths         []string
+	Root          string
+	Routes        []cfg.Route // Roots other than Root for repos matching their patterns.
+}
+
+// Rm executes the "git get rm" command.
+// It removes the repos at given paths together with their parent directories left empty, up to the repos root.
+// Repos outside of the root (and the roots of routes) and repos with work which might get lost
+// (eg, uncommitted files, stashes, unpushed commits or other repos nested inside) are removed only when forced.
+func Rm(conf *RmCfg) error {
+	if err := validateArchive(conf.Archive, conf.ArchiveFormat); err != nil {
+		return err
+	}
+
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return err
+	}
+
+	// Work on a copy with an absolute root, so it can be compared with the absolute repo paths.
+	conf = &RmCfg{
+		Archive:       conf.Archive,
+		ArchiveFormat: conf.ArchiveFormat,
+		Force:         conf.Force,
+		Paths:         conf.Paths,
+		Root:          root,
+		Routes:        conf.Routes,
+	}
+
+	var errs []error
+
+	for _, path := range conf.Paths {
+		if err := rmRepo(conf, resolveRepoPath(allRoots(conf.Root, cfg.Roots(conf.Routes, conf.Root)), path)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed removing repositories:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// validateArchive checks the archive format, if archiving is enabled.
+func validateArchive(archive string, format string) error {
+	if archive != "" && !slices.Contains(cfg.AllowedArchive, format) {
+		return fmt.Errorf("%w, allowed values: [%s]", ErrInvalidArchiveFormat, strings.Join(cfg.AllowedArchive, ", "))
+	}
+
+	return nil
+}
+
+// resolveRepoPath returns an absolute path to a repo. The path can be given relative to the current directory
+// or, if it doesn't exist there, relative to one of the roots (eg, "github.com/grdl/git-get").
+// The first root where the path exists is used, or the first root if it doesn't exist in any of them.
+func resolveRepoPath(roots []string, path string) string {
+	if !filepath.IsAbs(path) {
+		if _, err := os.Stat(path); err != nil {
+			rel := path
+			path = filepath.Join(roots[0], rel)
+
+			for _, root := range roots {
+				if _, err := os.Stat(filepath.Join(root, rel)); err == nil {
+					path = filepath.Join(root, rel)
+
+					break
+				}
+			}
+		}
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	return filepath.Clean(path)
+}
+
+func rmRepo(conf *RmCfg, path string) error {
+	roots := allRoots(conf.Root, cfg.Roots(conf.Routes, conf.Root))
+	if slices.Contains(roots, path) {
+		return fmt.Errorf("%w %s", errRemoveRoot, path)
+	}
+
+	if !conf.Force && rootOf(roots, path) == "" {
+		return fmt.Errorf("%w: %s; use --force to remove it anyway", errOutsideRoots, path)
+	}
+
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return fmt.Errorf("%w: %s", errNotARepo, path)
+	}
+
+	repo, err := git.Open(path)
+	if err != nil {
+		return err
+	}
+
+	if !conf.Force {
+		blockers, err := removalBlockers(repo)
+		if err != nil {
+			return err
+		}
+
+		if len(blockers) > 0 {
+			return fmt.Errorf("%w %s: %s; use --force to remove it anyway", ErrUnsafeRemove, path, strings.Join(blockers, ", "))
+		}
+	}
+
+	if conf.Archive != "" {
+		file, err := archiveRepo(repo, conf)
+		if err != nil {
+			return fmt.Errorf("failed archiving %s, not removing it: %w", path, err)
+		}
+
+		fmt.Printf("archived %s to %s\n", path, file)
+	}
+
+	// Parent directories are cleaned up to the root containing the repo. Outside of the roots, none of them are removed.
+	stop := rootOf(roots, path)
+	if stop == "" {
+		stop = filepath.Dir(path)
+	}
+
+	if err := repo.Remove(stop); err != nil {
+		return err
+	}
+
+	fmt.Printf("removed %s\n", path)
+
+	return nil
+}
+
+// removalBlockers returns descriptions of the work which would be lost by removing the repo,
+// eg "2 uncommitted files" or "local-only branch feature (3 commits)".
+func removalBlockers(repo *git.Repo) ([]string, error) {
+	var blockers []string
+
+	uncommitted, err := repo.Uncommitted()
+	if err != nil {
+		return nil, err
+	}
+
+	if uncommitted > 0 {
+		blockers = append(blockers, out.Plural(uncommitted, "uncommitted file"))
+	}
+
+	untracked, err := repo.Untracked()
+	if err != nil {
+		return nil, err
+	}
+
+	if untracked > 0 {
+		blockers = append(blockers, out.Plural(untracked, "untracked file"))
+	}
+
+	stashes, err := repo.Stashes()
+	if err != nil {
+		return nil, err
+	}
+
+	if stashes > 0 {
+		blockers = append(blockers, out.Plural(stashes, "stash"))
+	}
+
+	operation, err := repo.Operation()
+	if err != nil {
+		return nil, err
+	}
+
+	if operation != "" {
+		blockers = append(blockers, operation+" in progress")
+	}
+
+	nested, err := nestedRepos(repo.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range nested {
+		blockers = append(blockers, "nested repository "+path)
+	}
+
+	// An empty repo doesn't have any branches with commits which could be lost.
+	if dates, err := repo.LastCommitDates(); err != nil || len(dates) == 0 {
+		return blockers, err
+	}
+
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, branch := range branches {
+		blocker, err := branchBlocker(repo, branch)
+		if err != nil {
+			return nil, err
+		}
+
+		if blocker != "" {
+			blockers = append(blockers, blocker)
+		}
+	}
+
+	return blockers, nil
+}
+
+// nestedRepos returns the paths (relative to dir) of repos nested inside the repo at dir, which would be removed together with it.
+// Submodules aren't included, their .git is a file pointing into the .git directory of the repo.
+func nestedRepos(dir string) ([]string, error) {
+	var nested []string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() || entry.Name() != ".git" {
+			return nil
+		}
+
+		if parent := filepath.Dir(path); parent != dir {
+			rel, err := filepath.Rel(dir, parent)
+			if err != nil {
+				return err
+			}
+
+			nested = append(nested, rel)
+		}
+
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed looking for nested repositories: %w", err)
+	}
+
+	return nested, nil
+}
+
+// branchBlocker describes commits of a branch which don't exist on any remote or returns an empty string if there are none.
+func branchBlocker(repo *git.Repo, branch string) (string, error) {
+	upstream, err := repo.Upstream(branch)
+	if err != nil {
+		return "", err
+	}
+
+	if upstream != "" {
+		ahead, _, err := repo.AheadBehind(branch, upstream)
+		if err != nil || ahead == 0 {
+			return "", err
+		}
+
+		return fmt.Sprintf("branch %s (%s ahead of %s)", branch, out.Plural(ahead, "commit"), upstream), nil
+	}
+
+	unpushed, err := repo.Unpushed(branch)
+	if err != nil || unpushed == 0 {
+		return "", err
+	}
+
+	return fmt.Sprintf("local-only branch %s (%s)", branch, out.Plural(unpushed, "commit")), nil
+}
+
+// archiveRepo writes the repo into the archive directory and returns the path of the archive file.
+// The file is named after the repo path relative to the root, eg "github.com_grdl_git-get-20240102-150405.tar.gz".
+func archiveRepo(repo *git.Repo, conf *RmCfg) (string, error) {
+	if dir, err := filepath.Abs(conf.Archive); err != nil || isInside(repo.Path(), dir) {
+		return "", fmt.Errorf("%w: archive directory %s is inside the repository", errInvalidArchiveDir, conf.Archive)
+	}
+
+	if err := os.MkdirAll(conf.Archive, 0755); err != nil {
+		return "", fmt.Errorf("failed creating archive directory: %w", err)
+	}
+
+	name := filepath.Base(repo.Path())
+	if isInside(conf.Root, repo.Path()) {
+		rel, _ := filepath.Rel(conf.Root, repo.Path())
+		name = strings.ReplaceAll(filepath.ToSlash(rel), "/", "_")
+	}
+
+	name += "-" + time.Now().Format("20060102-150405")
+
+	ext := ".tar.gz"
+	if conf.ArchiveFormat == cfg.ArchiveBundle {
+		ext = ".bundle"
+	}
+
+	file, err := filepath.Abs(filepath.Join(conf.Archive, name+ext))
+	if err != nil {
+		return "", err
+	}
+
+	// Make sure we never overwrite (nor clean up after a failure) an existing archive.
+	if _, err := os.Stat(file); err == nil {
+		return "", fmt.Errorf("failed creating archive %s: %w", file, fs.ErrExist)
+	}
+
+	if conf.ArchiveFormat == cfg.ArchiveBundle {
+		err = repo.Bundle(file)
+	} else {
+		err = archiveTar(repo.Path(), file)
+	}
+
+	if err != nil {
+		os.Remove(file)
+
+		return "", err
+	}
+
+	return file, nil
+}
+
+// isInside checks if path is equal to dir or is inside of it. Both paths must be absolute.
+func isInside(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// archiveTar writes the whole directory (including the .git directory and ignored files) into a gzipped tarball.
+// Paths inside the tarball are prefixed with the name of the directory.
+func archiveTar(dir string, file string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed creating archive: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	base := filepath.Dir(dir)
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		return addToTar(tw, base, path, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("failed archiving %s: %w", dir, err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// addToTar writes a single file, directory or symlink into the tarball. Other file types (eg, sockets) are skipped.
+func addToTar(tw *tar.Writer, base string, path string, entry fs.DirEntry) error {
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+
+	var link string
+	if entry.Type()&fs.ModeSymlink != 0 {
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	} else if !entry.IsDir() && !entry.Type().IsRegular() {
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return err
+	}
+
+	header.Name = filepath.ToSlash(rel)
+	if entry.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if !entry.Type().IsRegular() {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	_, err = io.Copy(tw, src)
+
+	return err
+}
@@ -0,0 +1,169 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovalBlockers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		repoMaker func(*testing.T) *test.Repo
+		want      []string
+	}{
+		{
+			name:      "empty",
+			repoMaker: test.RepoEmpty,
+			want:      nil,
+		},
+		{
+			name:      "clean clone",
+			repoMaker: test.RepoWithBranchWithUpstream,
+			want:      nil,
+		},
+		{
+			name:      "branch without upstream pushed to remote",
+			repoMaker: test.RepoWithBranchWithoutUpstream,
+			want:      nil,
+		},
+		{
+			name:      "no remote",
+			repoMaker: test.RepoWithCommit,
+			want:      []string{"local-only branch main (1 commit)"},
+		},
+		{
+			name:      "uncommitted and untracked",
+			repoMaker: test.RepoWithUncommittedAndUntracked,
+			want:      []string{"1 uncommitted file", "1 untracked file", "local-only branch main (1 commit)"},
+		},
+		{
+			name:      "branch ahead",
+			repoMaker: test.RepoWithBranchAhead,
+			want:      []string{"branch feature/branch (1 commit ahead of origin/feature/branch)"},
+		},
+		{
+			name:      "stashes",
+			repoMaker: test.RepoWithStash,
+			want:      []string{"2 stashes", "local-only branch main (1 commit)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo, err := git.Open(test.repoMaker(t).Path())
+			require.NoError(t, err)
+
+			got, err := removalBlockers(repo)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRm(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	archive := test.TempDir(t, "")
+
+	clean := filepath.Join(root, "github.com", "grdl", "clean")
+	dirty := filepath.Join(root, "github.com", "other", "dirty")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(clean), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(dirty), 0755))
+	require.NoError(t, os.Rename(test.RepoWithBranchWithUpstream(t).Path(), clean))
+	require.NoError(t, os.Rename(test.RepoWithUncommittedAndUntracked(t).Path(), dirty))
+
+	err := Rm(&RmCfg{
+		Archive:       archive,
+		ArchiveFormat: cfg.ArchiveTar,
+		Paths:         []string{"github.com/grdl/clean", dirty},
+		Root:          root,
+	})
+	require.ErrorIs(t, err, ErrUnsafeRemove)
+
+	assert.NoDirExists(t, filepath.Join(root, "github.com", "grdl"))
+	assert.DirExists(t, dirty)
+
+	archives, err := filepath.Glob(filepath.Join(archive, "github.com_grdl_clean-*.tar.gz"))
+	require.NoError(t, err)
+	assert.Len(t, archives, 1)
+
+	err = Rm(&RmCfg{Force: true, Paths: []string{dirty}, Root: root})
+	require.NoError(t, err)
+
+	assert.NoDirExists(t, filepath.Join(root, "github.com"))
+	assert.DirExists(t, root)
+}
+
+func TestRmRefusesRoot(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+
+	err := Rm(&RmCfg{Force: true, Paths: []string{root}, Root: root})
+	require.ErrorIs(t, err, errRemoveRoot)
+	assert.DirExists(t, root)
+}
+
+func TestRmRefusesOutsideRoots(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+	outside := test.RepoWithBranchWithUpstream(t).Path()
+
+	routed := filepath.Join(work, "github.com", "mycompany", "api")
+	require.NoError(t, os.MkdirAll(filepath.Dir(routed), 0755))
+	require.NoError(t, os.Rename(test.RepoWithBranchWithUpstream(t).Path(), routed))
+
+	conf := &RmCfg{Paths: []string{outside, routed}, Root: root}
+
+	err := Rm(conf)
+	require.ErrorIs(t, err, errOutsideRoots)
+	assert.DirExists(t, outside)
+	assert.DirExists(t, routed)
+
+	// Repos under the roots of routes can be removed, up to their root.
+	conf.Paths = []string{routed}
+	conf.Routes = []cfg.Route{{Pattern: "github.com/mycompany", Root: work}}
+	require.NoError(t, Rm(conf))
+	assert.NoDirExists(t, filepath.Join(work, "github.com"))
+	assert.DirExists(t, work)
+
+	conf.Paths, conf.Force = []string{outside}, true
+	require.NoError(t, Rm(conf))
+	assert.NoDirExists(t, outside)
+}
+
+func TestRmRefusesNestedRepos(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	parent := filepath.Join(root, "github.com", "grdl", "parent")
+	nested := filepath.Join(parent, "vendor", "nested")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(parent), 0755))
+	require.NoError(t, os.Rename(test.RepoWithBranchWithUpstream(t).Path(), parent))
+	require.NoError(t, os.MkdirAll(filepath.Dir(nested), 0755))
+	require.NoError(t, os.Rename(test.RepoWithCommit(t).Path(), nested))
+
+	// Ignored, so the nested repo doesn't show up as untracked files of the parent.
+	require.NoError(t, os.WriteFile(filepath.Join(parent, ".git", "info", "exclude"), []byte("vendor/\n"), 0644))
+
+	err := Rm(&RmCfg{Paths: []string{parent}, Root: root})
+	require.ErrorIs(t, err, ErrUnsafeRemove)
+	assert.ErrorContains(t, err, "nested repository "+filepath.Join("vendor", "nested"))
+	assert.DirExists(t, nested)
+}
@@ -0,0 +1,143 @@
+package pkg
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+)
+
+// routeRoot returns the root a repo is cloned into: the root of the most specific route matching the repo
+// or the default root if none matches. Routes matching deeper paths are more specific, eg "github.com/mycompany/*"
+// wins over "github.com".
+func routeRoot(root string, routes []cfg.Route, url *url.URL) string {
+	repo := URLToPath(*url, false)
+	depth := -1
+
+	for _, route := range routes {
+		if d := matchRoute(route.Pattern, repo); d > depth {
+			root, depth = route.Root, d
+		}
+	}
+
+	return root
+}
+
+// matchRoute checks if a pattern matches a repo path (eg, "github.com/grdl/git-get") or any of its parent directories,
+// so a host matches all of its repos and "gitlab.com/group/*" matches repos in subgroups too.
+// It returns the depth of the matched path or -1 if there's no match.
+func matchRoute(pattern string, repo string) int {
+	pattern = strings.Trim(pattern, "/")
+
+	for dir := repo; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if ok, _ := path.Match(pattern, dir); ok {
+			return strings.Count(dir, "/")
+		}
+	}
+
+	return -1
+}
+
+// findRoots finds repositories under each root.
+// Roots which don't exist or have no repos are skipped, unless none of the roots has any repos.
+func findRoots(roots []string) ([]*git.RepoFinder, error) {
+	var (
+		finders []*git.RepoFinder
+		errs    []error
+	)
+
+	for _, root := range roots {
+		finder := git.NewRepoFinder(root)
+		if err := finder.Find(); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		finders = append(finders, finder)
+	}
+
+	if len(finders) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return finders, nil
+}
+
+// reposOf returns the repos found by the finders. Repos found under multiple (nested) roots are returned once.
+func reposOf(finders []*git.RepoFinder) []*git.Repo {
+	var repos []*git.Repo
+
+	seen := map[string]bool{}
+
+	for _, finder := range finders {
+		for _, repo := range finder.Repos() {
+			if !seen[repo.Path()] {
+				seen[repo.Path()] = true
+				repos = append(repos, repo)
+			}
+		}
+	}
+
+	return repos
+}
+
+// loadRootsStatuses finds repositories under each root and loads their statuses. Repos found under multiple (nested) roots are loaded once.
+// Roots which don't exist or have no repos are skipped, unless none of the roots has any repos.
+func loadRootsStatuses(roots []string, opts git.LoadOpts) ([]*git.Status, error) {
+	finders, err := findRoots(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadFound(finders, opts), nil
+}
+
+// loadFound loads the statuses of repos found by the finders. Repos found under multiple (nested) roots are returned once.
+func loadFound(finders []*git.RepoFinder, opts git.LoadOpts) []*git.Status {
+	var statuses []*git.Status
+
+	seen := map[string]bool{}
+
+	for _, finder := range finders {
+		for _, status := range finder.LoadAll(opts) {
+			if !seen[status.Path()] {
+				seen[status.Path()] = true
+				statuses = append(statuses, status)
+			}
+		}
+	}
+
+	return statuses
+}
+
+// rootOf returns the innermost of the roots containing the path or an empty string if none does.
+func rootOf(roots []string, path string) string {
+	var res string
+
+	for _, root := range roots {
+		if isInside(root, path) && len(root) > len(res) {
+			res = root
+		}
+	}
+
+	return res
+}
+
+// allRoots returns the default root followed by other roots, without duplicates.
+func allRoots(root string, others []string) []string {
+	roots := []string{filepath.Clean(root)}
+
+	for _, other := range others {
+		if other = filepath.Clean(other); !slices.Contains(roots, other) {
+			roots = append(roots, other)
+		}
+	}
+
+	return roots
+}
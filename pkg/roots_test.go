@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteRoot(t *testing.T) {
+	t.Parallel()
+
+	routes := []cfg.Route{
+		{Pattern: "github.com", Root: "/github"},
+		{Pattern: "github.com/mycompany/*", Root: "/work/src"},
+		{Pattern: "gitlab.com/group", Root: "/group"},
+		{Pattern: "gitlab.example.com/", Root: "/gitlab"},
+	}
+
+	var tests = []struct {
+		url  string
+		want string
+	}{
+		{url: "grdl/git-get", want: "/github"},
+		{url: "mycompany/api", want: "/work/src"},
+		{url: "https://github.com/mycompany/api.git", want: "/work/src"},
+		{url: "https://gitlab.com/group/sub/repo", want: "/group"},
+		{url: "https://gitlab.com/other/repo", want: "/repositories"},
+		{url: "git@gitlab.example.com:2222/team/repo", want: "/gitlab"},
+		{url: "https://codeberg.org/mycompany/api", want: "/repositories"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			t.Parallel()
+
+			url, err := ParseURL(test.url, "github.com", "ssh")
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, routeRoot("/repositories", routes, url))
+		})
+	}
+}
+
+func TestGetRoutedDumpOpts(t *testing.T) {
+	t.Parallel()
+
+	conf := &GetCfg{
+		DefHost:   "github.com",
+		DefScheme: "ssh",
+		Root:      "/repositories",
+		Routes:    []cfg.Route{{Pattern: "github.com/mycompany/*", Root: "/work/src"}},
+	}
+
+	opts, err := dumpCloneOpts(conf, parsedLine{rawurl: "mycompany/api", path: "api"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/work/src", "api"), opts.Path)
+	assert.True(t, opts.Pinned, "repos with a path override are pinned to it")
+
+	opts, err = dumpCloneOpts(conf, parsedLine{rawurl: "grdl/git-get"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/repositories", "github.com", "grdl", "git-get"), opts.Path)
+	assert.False(t, opts.Pinned)
+}
+
+func TestLoadRootsStatuses(t *testing.T) {
+	t.Parallel()
+
+	personal := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+	nested := filepath.Join(personal, "nested")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	repoWithRemote(t, personal, "dotfiles", "git@github.com:grdl/dotfiles.git")
+	repoWithRemote(t, nested, "notes", "git@github.com:grdl/notes.git")
+	repoWithRemote(t, work, "api", "git@github.com:mycompany/api.git")
+
+	missing := filepath.Join(work, "missing")
+	roots := allRoots(personal, []string{work, nested, missing, personal + string(filepath.Separator)})
+	assert.Equal(t, []string{personal, work, nested, missing}, roots)
+
+	statuses, err := loadRootsStatuses(roots, git.LoadOpts{})
+	require.NoError(t, err)
+	assert.Len(t, statuses, 3)
+
+	_, err = loadRootsStatuses([]string{missing}, git.LoadOpts{})
+	assert.ErrorIs(t, err, git.ErrDirNotExist)
+}
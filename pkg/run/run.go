@@ -35,8 +35,13 @@ type Cmd struct {
 func Git(args ...string) *Cmd {
 	ctx := context.Background()
 
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// Commands like "git status" opportunistically refresh the index, which is a write operation.
+	// We only read the repos status and don't want to modify them (or trigger filesystem watchers).
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+
 	return &Cmd{
-		cmd:  exec.CommandContext(ctx, "git", args...),
+		cmd:  cmd,
 		args: strings.Join(args, " "),
 	}
 }
@@ -131,6 +136,11 @@ func (e GitError) Error() string {
 	return fmt.Sprintf("git %s failed on %s: %s", e.Args, e.Path, msg)
 }
 
+// Unwrap returns the underlying error, eg *exec.ExitError which holds the exit code of the command.
+func (e GitError) Unwrap() error {
+	return e.Err
+}
+
 func lines(output []byte) []string {
 	lines := strings.TrimSuffix(string(output), "\n")
 
@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+)
+
+var ErrInvalidSort = errors.New("invalid sort key")
+
+// descPrefix reverses the sort order when prepended to a sort key, eg "-date".
+const descPrefix = "-"
+
+// validateSort checks if the --sort flag value is one of cfg.AllowedSort, optionally prefixed with descPrefix.
+func validateSort(sort string) error {
+	if !slices.Contains(cfg.AllowedSort, strings.TrimPrefix(sort, descPrefix)) {
+		return fmt.Errorf("%w %q, allowed values: [%s], prefix with %q to sort in descending order",
+			ErrInvalidSort, sort, strings.Join(cfg.AllowedSort, ", "), descPrefix)
+	}
+
+	return nil
+}
+
+// sortStatuses sorts the statuses in place by a given sort flag value. Statuses with equal keys are sorted by path.
+// The value must be validated with validateSort first.
+func sortStatuses(statuses []*git.Status, sort string) {
+	key, desc := strings.CutPrefix(sort, descPrefix)
+
+	slices.SortStableFunc(statuses, func(a, b *git.Status) int {
+		c := compareBy(key, a, b)
+		if desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Path(), b.Path())
+	})
+}
+
+// compareBy compares two statuses by a given sort key in ascending order.
+func compareBy(key string, a, b *git.Status) int {
+	switch key {
+	case cfg.SortAge:
+		// Least recently active repos go first.
+		return a.LastActivity().Compare(b.LastActivity())
+	case cfg.SortAhead:
+		return cmp.Compare(a.Ahead(), b.Ahead())
+	case cfg.SortBehind:
+		return cmp.Compare(a.Behind(), b.Behind())
+	case cfg.SortDate:
+		return a.LastCommitDate().Compare(b.LastCommitDate())
+	case cfg.SortDirty:
+		return cmp.Compare(a.Changes(), b.Changes())
+	case cfg.SortHost:
+		return compareHosts(host(a), host(b))
+	case cfg.SortPath:
+		return strings.Compare(a.Path(), b.Path())
+	case cfg.SortSize:
+		return cmp.Compare(a.Size(), b.Size())
+	default:
+		return 0
+	}
+}
+
+// compareHosts compares host names. Repos without a known host go last.
+func compareHosts(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// host returns the host name of the repo's remote or an empty string if it has no remote or it can't be parsed.
+func host(status *git.Status) string {
+	if status.Remote() == "" {
+		return ""
+	}
+
+	url, err := parseRawURL(status.Remote())
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(url.Hostname())
+}
@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortStatuses(t *testing.T) {
+	t.Parallel()
+
+	statuses := testStatuses(t, `[
+		{"path": "/root/a", "remote": "git@gitlab.com:grdl/a.git", "ahead": 1, "changes": 0, "commit": {"date": "2024-01-01T00:00:00Z"}, "usage": {"worktree": 100, "git": 50}},
+		{"path": "/root/b", "remote": "https://github.com/grdl/b", "ahead": 0, "behind": 3, "changes": 5, "commit": {"date": "2022-01-01T00:00:00Z"}, "usage": {"worktree": 10, "lfs": 500}},
+		{"path": "/root/c", "remote": "", "ahead": 1, "changes": 2, "commit": {"date": "2023-01-01T00:00:00Z"}, "usage": {"git": 20}}
+	]`)
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{sort: "path", want: []string{"/root/a", "/root/b", "/root/c"}},
+		{sort: "-path", want: []string{"/root/c", "/root/b", "/root/a"}},
+		{sort: "host", want: []string{"/root/b", "/root/a", "/root/c"}},
+		{sort: "date", want: []string{"/root/b", "/root/c", "/root/a"}},
+		{sort: "-date", want: []string{"/root/a", "/root/c", "/root/b"}},
+		{sort: "-dirty", want: []string{"/root/b", "/root/c", "/root/a"}},
+		{sort: "-ahead", want: []string{"/root/a", "/root/c", "/root/b"}},
+		{sort: "-behind", want: []string{"/root/b", "/root/a", "/root/c"}},
+		{sort: "size", want: []string{"/root/c", "/root/a", "/root/b"}},
+		{sort: "-size", want: []string{"/root/b", "/root/a", "/root/c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			t.Parallel()
+			require.NoError(t, validateSort(test.sort))
+
+			sorted := selectStatuses(&ListCfg{Sort: test.sort}, statuses)
+
+			var got []string
+			for _, status := range sorted {
+				got = append(got, status.Path())
+			}
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestValidateSort(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateSort("-age"))
+	assert.ErrorIs(t, validateSort("foo"), ErrInvalidSort)
+	assert.ErrorIs(t, validateSort("--path"), ErrInvalidSort)
+}
+
+// testStatuses creates statuses from their JSON representation.
+func testStatuses(t *testing.T, data string) []*git.Status {
+	t.Helper()
+
+	var statuses []*git.Status
+	require.NoError(t, json.Unmarshal([]byte(data), &statuses))
+
+	return statuses
+}
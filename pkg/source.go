@@ -0,0 +1,239 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grdl/git-get/pkg/git"
+)
+
+var (
+	errInvalidSource     = errors.New("invalid dump source")
+	errUnreachableSource = errors.New("failed fetching dump file")
+	errInvalidInclude    = errors.New("invalid include")
+	errOutsideRepo       = errors.New("file is outside of the repository")
+)
+
+// Prefix of dump files stored in git repos, eg "git+https://github.com/grdl/repos#team/backend.txt".
+const gitSourcePrefix = "git+"
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// dumpSource fetches dump files which are not stored locally and keeps their copies in a cache directory.
+// If a source can't be reached, the cached copy is used instead, so repos can still be cloned when offline.
+type dumpSource struct {
+	cache     string
+	defHost   string
+	defScheme string
+}
+
+// newDumpSource creates a dumpSource caching the files in the user's cache directory, eg ~/.cache/git-get/dumps.
+func newDumpSource(conf *GetCfg) (*dumpSource, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dumpSource{
+		cache:     filepath.Join(cache, "git-get", "dumps"),
+		defHost:   conf.DefHost,
+		defScheme: conf.DefScheme,
+	}, nil
+}
+
+// isRemoteSource checks if a dump file has to be fetched before it's parsed.
+func isRemoteSource(source string) bool {
+	return strings.HasPrefix(source, gitSourcePrefix) || strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// includeCheck checks if a dump file can include a file at a given absolute path.
+type includeCheck func(path string) error
+
+// fetch returns a path to a local copy of a dump file and a check of files it can include. Source can be a local path,
+// an http(s) URL or a reference to a file in a git repo, eg "git+https://github.com/grdl/repos#team/backend.txt".
+// Local paths are returned as they are and can include any file, so their check is nil.
+func (s *dumpSource) fetch(source string) (string, includeCheck, error) {
+	switch {
+	case strings.HasPrefix(source, gitSourcePrefix):
+		return s.fetchGit(source)
+	case isRemoteSource(source):
+		return s.fetchHTTP(source)
+	default:
+		return source, nil, nil
+	}
+}
+
+// fetchHTTP downloads a dump file into the cache.
+// The downloaded copy is stored away from the files it could include, so includes aren't allowed.
+func (s *dumpSource) fetchHTTP(source string) (string, includeCheck, error) {
+	file := filepath.Join(s.cache, cacheKey(source))
+
+	noIncludes := func(string) error {
+		return fmt.Errorf("%w: dump files fetched over http(s) can't include other files, use a git+<URL>#<path> source instead", errInvalidInclude)
+	}
+
+	err := download(source, file)
+	if err != nil {
+		file, err = useCached(source, file, err)
+	}
+
+	return file, noIncludes, err
+}
+
+// fetchGit clones (or updates a previous clone of) the repo into the cache and returns the path of the file in its worktree.
+// Dump files included by the file are resolved inside the repo, same as for local files, but they can't be outside of it.
+func (s *dumpSource) fetchGit(source string) (string, includeCheck, error) {
+	rawURL, path, ok := cutLast(strings.TrimPrefix(source, gitSourcePrefix), "#")
+	if !ok || rawURL == "" || path == "" {
+		return "", nil, fmt.Errorf("%w %q, use git+<URL>#<path to file>", errInvalidSource, source)
+	}
+
+	url, err := ParseURL(rawURL, s.defHost, s.defScheme)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w %q: %w", errInvalidSource, source, err)
+	}
+
+	dir := filepath.Join(s.cache, cacheKey(url.String()))
+	file := filepath.Join(dir, filepath.FromSlash(path))
+
+	if exists, _ := git.Exists(dir); exists {
+		repo, err := git.Open(dir)
+		if err == nil {
+			err = repo.ResetToUpstream()
+		}
+
+		if err != nil {
+			if file, err = useCached(source, file, err); err != nil {
+				return "", nil, err
+			}
+
+			return checkSourceFile(source, dir, file)
+		}
+	} else {
+		if err := os.MkdirAll(s.cache, 0755); err != nil {
+			return "", nil, fmt.Errorf("failed creating dump files cache: %w", err)
+		}
+
+		if _, err := git.Clone(&git.CloneOpts{URL: url, Path: dir, Depth: 1, Quiet: true}); err != nil {
+			return "", nil, fmt.Errorf("%w %s: %w", errUnreachableSource, source, err)
+		}
+	}
+
+	if _, err := os.Stat(file); err != nil {
+		return "", nil, fmt.Errorf("%w %s: file %s not found in the repository", errUnreachableSource, source, path)
+	}
+
+	return checkSourceFile(source, dir, file)
+}
+
+// checkSourceFile checks if the dump file fetched from a git repo is inside the repo, so eg "#../file" can't read local files.
+// It returns the file with the check of its includes.
+func checkSourceFile(source string, dir string, file string) (string, includeCheck, error) {
+	if err := checkInside(dir, file); err != nil {
+		return "", nil, fmt.Errorf("%w %q: %w", errInvalidSource, source, err)
+	}
+
+	return file, insideDir(dir), nil
+}
+
+// insideDir returns a check allowing only includes of files inside dir.
+func insideDir(dir string) includeCheck {
+	return func(path string) error {
+		if err := checkInside(dir, path); err != nil {
+			return fmt.Errorf("%w %s: dump files fetched from git repos can only include files from the same repo: %w", errInvalidInclude, path, err)
+		}
+
+		return nil
+	}
+}
+
+// checkInside checks if path is inside dir. Symlinks are resolved first, so they can't point outside of it.
+func checkInside(dir string, path string) error {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+
+	if rel, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w: %s", errOutsideRepo, path)
+	}
+
+	return nil
+}
+
+// download writes the content of url into a file. The file is replaced only when the whole content is downloaded.
+func download(url string, file string) error {
+	resp, err := httpClient.Get(url) //nolint:noctx // The client has a timeout.
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed creating dump files cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// useCached returns the cached copy of a dump file which couldn't be fetched, or the fetch error if there's no copy.
+func useCached(source string, file string, fetchErr error) (string, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", fmt.Errorf("%w %s: %w", errUnreachableSource, source, fetchErr)
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: failed fetching dump file %s, using a copy cached on %s: %v\n",
+		source, info.ModTime().Format(time.DateTime), fetchErr)
+
+	return file, nil
+}
+
+// cacheKey returns a file name under which a source is cached.
+func cacheKey(source string) string {
+	sum := sha256.Sum256([]byte(source))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s string, sep string) (before string, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
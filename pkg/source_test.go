@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/grdl/git-get/pkg/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchHTTPSource(t *testing.T) {
+	t.Parallel()
+
+	content := "grdl/git-get\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos.txt" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Write([]byte(content)) //nolint:errcheck
+	}))
+
+	source := &dumpSource{cache: test.TempDir(t, "")}
+
+	file, _, err := source.fetch(server.URL + "/repos.txt")
+	require.NoError(t, err)
+	assertFileContent(t, file, "grdl/git-get\n")
+
+	content = "grdl/git-get\ngrdl/homebrew-tap\n"
+	file, _, err = source.fetch(server.URL + "/repos.txt")
+	require.NoError(t, err)
+	assertFileContent(t, file, content)
+
+	_, _, err = source.fetch(server.URL + "/missing.txt")
+	assert.ErrorIs(t, err, errUnreachableSource)
+
+	// When the server is gone, the cached copy is used.
+	server.Close()
+
+	file, _, err = source.fetch(server.URL + "/repos.txt")
+	require.NoError(t, err)
+	assertFileContent(t, file, content)
+
+	_, _, err = (&dumpSource{cache: test.TempDir(t, "")}).fetch(server.URL + "/repos.txt")
+	assert.ErrorIs(t, err, errUnreachableSource)
+}
+
+func TestFetchGitSource(t *testing.T) {
+	t.Parallel()
+
+	repo := test.RepoWithFile(t, "repos.txt", "grdl/git-get\n")
+	source := &dumpSource{cache: test.TempDir(t, "")}
+
+	file, _, err := source.fetch("git+file://" + repo.Path() + "#repos.txt")
+	require.NoError(t, err)
+	assertFileContent(t, file, "grdl/git-get\n")
+
+	repo.CommitFile("repos.txt", "grdl/homebrew-tap\n")
+
+	file, _, err = source.fetch("git+file://" + repo.Path() + "#repos.txt")
+	require.NoError(t, err)
+	assertFileContent(t, file, "grdl/homebrew-tap\n")
+
+	_, _, err = source.fetch("git+file://" + repo.Path() + "#missing.txt")
+	assert.ErrorIs(t, err, errUnreachableSource)
+
+	_, _, err = source.fetch("git+file://" + repo.Path())
+	assert.ErrorIs(t, err, errInvalidSource)
+
+	// When the repo is gone, the cached copy is used.
+	require.NoError(t, os.RemoveAll(repo.Path()))
+
+	file, _, err = source.fetch("git+file://" + repo.Path() + "#repos.txt")
+	require.NoError(t, err)
+	assertFileContent(t, file, "grdl/homebrew-tap\n")
+
+	_, _, err = (&dumpSource{cache: test.TempDir(t, "")}).fetch("git+file://" + filepath.Join(repo.Path(), "missing") + "#repos.txt")
+	assert.ErrorIs(t, err, errUnreachableSource)
+}
+
+func assertFileContent(t *testing.T, file string, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
+
+func TestRemoteSourceIncludes(t *testing.T) {
+	t.Parallel()
+
+	outside := filepath.Join(test.TempDir(t, ""), "local.txt")
+	writeDumpFile(t, outside, "grdl/secret\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("include team.txt\n")) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	source := &dumpSource{cache: test.TempDir(t, "")}
+
+	file, check, err := source.fetch(server.URL + "/repos.txt")
+	require.NoError(t, err)
+
+	_, err = parseDumpFile(file, check)
+	assert.ErrorIs(t, err, errInvalidInclude, "http(s) dump files can't include other files")
+
+	repo := test.RepoWithFile(t, "repos.txt", "grdl/git-get\ninclude backend.txt\n")
+	repo.CommitFile("backend.txt", "grdl/homebrew-tap\n")
+
+	file, check, err = source.fetch("git+file://" + repo.Path() + "#repos.txt")
+	require.NoError(t, err)
+
+	got, err := parseDumpFile(file, check)
+	require.NoError(t, err)
+	assert.Equal(t, []parsedLine{{rawurl: "grdl/git-get"}, {rawurl: "grdl/homebrew-tap"}}, got)
+
+	for _, include := range []string{outside, "../../" + filepath.Base(outside)} {
+		repo.CommitFile("escape.txt", "include "+include+"\n")
+
+		file, check, err = source.fetch("git+file://" + repo.Path() + "#escape.txt")
+		require.NoError(t, err)
+
+		_, err = parseDumpFile(file, check)
+		assert.ErrorIs(t, err, errInvalidInclude, "git dump files can only include files from the same repo: %s", include)
+	}
+}
+
+func TestGitSourceOutsideRepo(t *testing.T) {
+	t.Parallel()
+
+	cache := test.TempDir(t, "")
+	source := &dumpSource{cache: cache}
+
+	outside := filepath.Join(cache, "x")
+	writeDumpFile(t, outside, "grdl/secret\n")
+
+	repo := test.RepoWithFile(t, "repos.txt", "grdl/git-get\n")
+
+	_, _, err := source.fetch("git+file://" + repo.Path() + "#../x")
+	assert.ErrorIs(t, err, errInvalidSource)
+
+	require.NoError(t, os.Symlink(outside, filepath.Join(repo.Path(), "link.txt")))
+	require.NoError(t, run.Git("add", "link.txt").OnRepo(repo.Path()).AndShutUp())
+	require.NoError(t, run.Git("commit", "-m", "link").OnRepo(repo.Path()).AndShutUp())
+
+	_, _, err = source.fetch("git+file://" + repo.Path() + "#link.txt")
+	assert.ErrorIs(t, err, errInvalidSource, "symlinks can't point outside of the repo")
+}
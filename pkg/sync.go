@@ -0,0 +1,338 @@
+package pkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+)
+
+var errSyncAborted = errors.New("sync aborted")
+
+// SyncCfg provides configuration for the Sync command.
+type SyncCfg struct {
+	Archive       string // Directory where the pruned repos are archived before removal. Archiving is skipped if empty.
+	ArchiveFormat string
+	DefHost       string
+	DefScheme     string
+	DryRun        bool
+	Manifest      string
+	Prune         bool
+	Root          string
+	Routes        []cfg.Route // Roots other than Root for repos matching their patterns. Their repos are synced too.
+	SkipHost      bool
+	Tags          []string // Sync only the repos with any of these tags, both in the manifest and under the roots.
+	Yes           bool
+}
+
+// Actions of a sync plan.
+const (
+	actionClone  = "clone"
+	actionUpdate = "update"
+	actionRemove = "remove"
+	actionKeep   = "keep" // Repo which is not in the manifest but isn't removed.
+	actionSkip   = "skip" // Manifest entry which can't be cloned.
+)
+
+// syncStep is a single step of a sync plan.
+type syncStep struct {
+	action string
+	path   string
+	opts   *git.CloneOpts // Set for repos listed in the manifest.
+	repo   *git.Repo      // Set for repos which already exist.
+	note   string         // Why the repo is kept or skipped.
+}
+
+// Sync executes the "git get sync" command.
+// It treats the manifest (a dump file) as the desired state of the root and the roots of routes: it clones the missing repos,
+// updates the existing ones and reports the repos under the roots which are not in the manifest.
+// With conf.Prune, the ones which are safe to remove are removed.
+// The plan is printed first and applied only after confirmation.
+func Sync(conf *SyncCfg) error {
+	if conf.Prune {
+		if err := validateArchive(conf.Archive, conf.ArchiveFormat); err != nil {
+			return err
+		}
+	}
+
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return err
+	}
+
+	dumpOpts, err := loadDump(&GetCfg{
+		DefHost:   conf.DefHost,
+		DefScheme: conf.DefScheme,
+		Dump:      conf.Manifest,
+		Root:      root,
+		Routes:    conf.Routes,
+		SkipHost:  conf.SkipHost,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Missing or empty roots are fine, all repos from the manifest routed there are cloned then.
+	var finders []*git.RepoFinder
+
+	for _, dir := range allRoots(root, cfg.Roots(conf.Routes, root)) {
+		finder := git.NewRepoFinder(dir)
+		if err := finder.Find(); err != nil && !errors.Is(err, git.ErrNoReposFound) && !errors.Is(err, git.ErrDirNotExist) {
+			return err
+		}
+
+		finders = append(finders, finder)
+	}
+
+	plan, err := planSync(dumpOpts, reposOf(finders), conf.Prune, conf.Tags)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(printPlan(plan, root))
+
+	if !hasChanges(plan) {
+		fmt.Println("Everything is in sync")
+	}
+
+	if conf.DryRun {
+		return nil
+	}
+
+	// Updates only fetch and fast-forward repos without local changes, so they are applied without asking.
+	updates := slices.DeleteFunc(slices.Clone(plan), func(step *syncStep) bool { return step.action != actionUpdate })
+	changes := slices.DeleteFunc(slices.Clone(plan), func(step *syncStep) bool { return step.action == actionUpdate })
+
+	rmConf := &RmCfg{Archive: conf.Archive, ArchiveFormat: conf.ArchiveFormat, Root: root, Routes: conf.Routes}
+
+	err = applySync(updates, rmConf)
+	if !hasChanges(changes) {
+		return err
+	}
+
+	if !conf.Yes && !confirm("Apply the plan?") {
+		return errors.Join(err, errSyncAborted)
+	}
+
+	return errors.Join(err, applySync(changes, rmConf))
+}
+
+// planSync compares the manifest entries with the repos found under the roots and returns the steps needed to reconcile them.
+// Repos not in the manifest are removed only when prune is true and there's no work which would be lost by removing them.
+// If tags are given, only the manifest entries and the repos not in the manifest with any of these tags are planned.
+func planSync(dumpOpts []*git.CloneOpts, repos []*git.Repo, prune bool, tags []string) ([]*syncStep, error) {
+	existing := make(map[string]*git.Repo, len(repos))
+	for _, repo := range repos {
+		existing[filepath.Clean(repo.Path())] = repo
+	}
+
+	var plan []*syncStep
+
+	wanted := make(map[string]bool, len(dumpOpts))
+
+	for _, opts := range dumpOpts {
+		path := filepath.Clean(opts.Path)
+
+		// Repos from the manifest without the wanted tags are neither synced nor treated as repos which are not in the manifest.
+		if !hasAnyTag(opts.Tags, tags) {
+			wanted[path] = true
+
+			continue
+		}
+
+		step := &syncStep{path: path}
+
+		switch {
+		case wanted[path]:
+			step.action, step.note = actionSkip, "listed more than once in the manifest"
+		case existing[path] != nil:
+			step.action, step.repo, step.opts = actionUpdate, existing[path], opts
+		default:
+			if exists, _ := git.Exists(path); exists {
+				step.action, step.note = actionSkip, "path exists and is not a repository"
+			} else {
+				step.action, step.opts = actionClone, opts
+			}
+		}
+
+		wanted[path] = true
+		plan = append(plan, step)
+	}
+
+	extra := slices.DeleteFunc(slices.Clone(repos), func(repo *git.Repo) bool { return wanted[filepath.Clean(repo.Path())] })
+
+	extra, err := reposWithTags(extra, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repo := range extra {
+		path := filepath.Clean(repo.Path())
+		step := &syncStep{action: actionKeep, path: path, repo: repo, note: "not in the manifest, use --prune to remove it"}
+
+		blockers, err := removalBlockers(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case len(blockers) > 0:
+			step.note = "not in the manifest, but has " + strings.Join(blockers, ", ")
+		case prune:
+			step.action, step.note = actionRemove, ""
+		}
+
+		plan = append(plan, step)
+	}
+
+	return plan, nil
+}
+
+// printPlan returns a line for each step of the plan, eg "clone   github.com/grdl/git-get".
+// Updates are left out, they report their results when they are applied.
+func printPlan(plan []*syncStep, root string) string {
+	var str strings.Builder
+
+	for _, step := range plan {
+		if step.action == actionUpdate {
+			continue
+		}
+
+		path := step.path
+		if rel, err := filepath.Rel(root, path); err == nil && isInside(root, path) {
+			path = rel
+		}
+
+		str.WriteString(fmt.Sprintf("%-7s %s", step.action, path))
+
+		if step.note != "" {
+			str.WriteString(" (" + step.note + ")")
+		}
+
+		str.WriteString("\n")
+	}
+
+	return str.String()
+}
+
+// hasChanges checks if the plan clones or removes any repos, which needs a confirmation.
+// Updates of existing repos don't count, they are always applied.
+func hasChanges(plan []*syncStep) bool {
+	return slices.ContainsFunc(plan, func(step *syncStep) bool {
+		return step.action == actionClone || step.action == actionRemove
+	})
+}
+
+// confirm asks a yes/no question and reads the answer from stdin. Anything other than "y" or "yes" means no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
+// applySync executes the plan. It continues when a step fails and returns the errors of all failed steps.
+func applySync(plan []*syncStep, rmConf *RmCfg) error {
+	var errs []error
+
+	for _, step := range plan {
+		var err error
+
+		switch step.action {
+		case actionClone:
+			fmt.Printf("Cloning %s...\n", step.opts.URL.String())
+			_, err = git.Clone(step.opts)
+		case actionUpdate:
+			err = updateRepo(step.repo, step.opts)
+		case actionRemove:
+			err = rmRepo(rmConf, step.path)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to %s %s: %w", step.action, step.path, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed syncing repositories:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// updateRepo adds the remotes and tags from the manifest which the repo is missing, fetches all remotes
+// and fast-forwards the current branch if it's behind its upstream and has no local changes.
+func updateRepo(repo *git.Repo, opts *git.CloneOpts) error {
+	if err := applyManifest(repo, opts); err != nil {
+		return err
+	}
+
+	if err := repo.Fetch(); err != nil {
+		return err
+	}
+
+	status := repo.LoadStatus(git.LoadOpts{})
+	if len(status.Errors()) > 0 {
+		return errors.New(strings.Join(status.Errors(), "\n"))
+	}
+
+	switch {
+	case status.Behind() == 0:
+		fmt.Printf("%s is up to date\n", repo.Path())
+	case status.Ahead() > 0 || status.Changes() > 0:
+		fmt.Printf("fetched %s, but not fast-forwarded because it has local changes\n", repo.Path())
+	default:
+		if err := repo.FastForward(); err != nil {
+			return err
+		}
+
+		fmt.Printf("updated %s\n", repo.Path())
+	}
+
+	return nil
+}
+
+// applyManifest adds the remotes from the manifest which the repo is missing and sets its tags, if the manifest lists any.
+// Repos with a custom path in the manifest are pinned there.
+// Existing remotes are never changed.
+func applyManifest(repo *git.Repo, opts *git.CloneOpts) error {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return err
+	}
+
+	for _, remote := range opts.Remotes {
+		if slices.ContainsFunc(remotes, func(r git.Remote) bool { return r.Name == remote.Name }) {
+			continue
+		}
+
+		if err := repo.AddRemote(remote, true); err != nil {
+			return err
+		}
+	}
+
+	if opts.Pinned {
+		if err := repo.SetPinned(); err != nil {
+			return err
+		}
+	}
+
+	if len(opts.Tags) == 0 {
+		return nil
+	}
+
+	tags, err := repo.Tags()
+	if err != nil || slices.Equal(tags, opts.Tags) {
+		return err
+	}
+
+	return repo.SetTags(opts.Tags)
+}
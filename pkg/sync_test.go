@@ -0,0 +1,143 @@
+package pkg
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	first := test.RepoWithCommit(t)
+	second := test.RepoWithCommit(t)
+
+	manifest := filepath.Join(test.TempDir(t, ""), "repos.txt")
+	writeDumpFile(t, manifest, "file://"+first.Path()+"\nfile://"+second.Path()+" tags=work\n")
+
+	conf := &SyncCfg{Manifest: manifest, Root: root, Yes: true}
+	require.NoError(t, Sync(conf))
+
+	firstClone := filepath.Join(root, URLToPath(url.URL{Path: first.Path()}, false))
+	secondClone := filepath.Join(root, URLToPath(url.URL{Path: second.Path()}, false))
+
+	assert.DirExists(t, filepath.Join(firstClone, ".git"))
+	assert.DirExists(t, filepath.Join(secondClone, ".git"))
+
+	// Repos not in the manifest: a clean one which can be pruned and one with untracked files which must be kept.
+	clean := cloneInto(t, test.RepoWithCommit(t), filepath.Join(root, "extra", "clean"))
+	dirty := cloneInto(t, test.RepoWithCommit(t), filepath.Join(root, "extra", "dirty"))
+	require.NoError(t, os.WriteFile(filepath.Join(dirty, "new"), []byte("new"), 0644))
+
+	first.CommitFile("CHANGELOG.md", "new release")
+
+	require.NoError(t, Sync(conf))
+	assert.DirExists(t, clean, "repos not in the manifest are removed only with --prune")
+	assert.FileExists(t, filepath.Join(firstClone, "CHANGELOG.md"))
+
+	conf.Prune = true
+	require.NoError(t, Sync(conf))
+	assert.NoDirExists(t, clean)
+	assert.DirExists(t, dirty)
+	assert.DirExists(t, secondClone)
+}
+
+func TestSyncTwoRoots(t *testing.T) {
+	t.Parallel()
+
+	personal := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+	first := test.RepoWithCommit(t)
+	second := test.RepoWithCommit(t)
+
+	manifest := filepath.Join(test.TempDir(t, ""), "repos.txt")
+	writeDumpFile(t, manifest, "file://"+first.Path()+"\nfile://"+second.Path()+"\n")
+
+	secondPath := URLToPath(url.URL{Path: second.Path()}, false)
+
+	conf := &SyncCfg{
+		Manifest: manifest,
+		Root:     personal,
+		Routes:   []cfg.Route{{Pattern: secondPath, Root: work}},
+		Yes:      true,
+	}
+	require.NoError(t, Sync(conf))
+
+	assert.DirExists(t, filepath.Join(personal, URLToPath(url.URL{Path: first.Path()}, false), ".git"))
+	assert.DirExists(t, filepath.Join(work, secondPath, ".git"))
+	assert.NoDirExists(t, filepath.Join(personal, secondPath))
+
+	// Repos under the root of the route which are not in the manifest are pruned too, up to that root.
+	clean := cloneInto(t, test.RepoWithCommit(t), filepath.Join(work, "extra", "clean"))
+
+	conf.Prune = true
+	require.NoError(t, Sync(conf))
+	assert.NoDirExists(t, clean)
+	assert.NoDirExists(t, filepath.Join(work, "extra"))
+	assert.DirExists(t, filepath.Join(work, secondPath, ".git"), "repos from the manifest under the root of their route are kept")
+}
+
+func TestPlanSync(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	existing := cloneInto(t, test.RepoWithCommit(t), filepath.Join(root, "existing"))
+	notARepo := filepath.Join(root, "not-a-repo")
+	require.NoError(t, os.Mkdir(notARepo, 0755))
+
+	repo, err := git.Open(existing)
+	require.NoError(t, err)
+
+	plan, err := planSync([]*git.CloneOpts{
+		{Path: existing},
+		{Path: filepath.Join(root, "missing")},
+		{Path: filepath.Join(root, "missing")},
+		{Path: notARepo},
+	}, []*git.Repo{repo}, false, nil)
+	require.NoError(t, err)
+
+	// Updates are applied without confirmation and report their results themselves, so they aren't printed.
+	assert.Equal(t, actionUpdate, plan[0].action)
+	assert.Equal(t, "clone   missing\nskip    missing (listed more than once in the manifest)\n"+
+		"skip    not-a-repo (path exists and is not a repository)\n", printPlan(plan, root))
+	assert.True(t, hasChanges(plan))
+
+	plan, err = planSync([]*git.CloneOpts{{Path: existing}}, []*git.Repo{repo}, false, nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, printPlan(plan, root))
+	assert.False(t, hasChanges(plan), "a manifest with only existing repos is in sync")
+
+	plan, err = planSync(nil, []*git.Repo{repo}, false, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "keep    existing (not in the manifest, use --prune to remove it)\n", printPlan(plan, root))
+	assert.False(t, hasChanges(plan))
+
+	// Repos from the manifest without the wanted tag are ignored, but they aren't treated as repos which are not in the manifest.
+	plan, err = planSync([]*git.CloneOpts{
+		{Path: existing},
+		{Path: filepath.Join(root, "missing"), Tags: []string{"infra"}},
+	}, []*git.Repo{repo}, true, []string{"infra"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "clone   missing\n", printPlan(plan, root))
+}
+
+// cloneInto clones a repo into a given path and returns the path.
+func cloneInto(t *testing.T, origin *test.Repo, path string) string {
+	t.Helper()
+
+	_, err := git.Clone(&git.CloneOpts{URL: &url.URL{Scheme: "file", Path: origin.Path()}, Path: path, Quiet: true})
+	require.NoError(t, err)
+
+	return path
+}
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+)
+
+var errInvalidTag = errors.New("invalid tag")
+
+// TagCfg provides configuration for the Tag command.
+type TagCfg struct {
+	Path   string
+	Remove bool
+	Root   string
+	Routes []cfg.Route // Roots other than Root, the repo path can be relative to them too.
+	Tags   []string
+}
+
+// Tag executes the "git get tag" command.
+// It adds tags to (or removes them from) a repo, or prints its tags if none are given.
+// Tags are stored in the repo's git config as "gitget.tags" values.
+func Tag(conf *TagCfg) error {
+	for _, tag := range conf.Tags {
+		if err := validateTag(tag); err != nil {
+			return err
+		}
+	}
+
+	root, err := filepath.Abs(conf.Root)
+	if err != nil {
+		return err
+	}
+
+	path := resolveRepoPath(allRoots(root, cfg.Roots(conf.Routes, root)), conf.Path)
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return fmt.Errorf("%w: %s", errNotARepo, path)
+	}
+
+	repo, err := git.Open(path)
+	if err != nil {
+		return err
+	}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return err
+	}
+
+	if len(conf.Tags) > 0 {
+		if conf.Remove {
+			tags = slices.DeleteFunc(tags, func(tag string) bool { return slices.Contains(conf.Tags, tag) })
+		} else {
+			tags = addTags(tags, conf.Tags)
+		}
+
+		if err := repo.SetTags(tags); err != nil {
+			return err
+		}
+	}
+
+	if len(tags) > 0 {
+		fmt.Println(strings.Join(tags, " "))
+	}
+
+	return nil
+}
+
+// validateTag checks if a tag can be stored in a dump file, where tags are separated with commas and options with whitespace.
+func validateTag(tag string) error {
+	if tag == "" || strings.ContainsAny(tag, ", \t") {
+		return fmt.Errorf("%w %q, tags can't be empty nor contain commas or whitespace", errInvalidTag, tag)
+	}
+
+	return nil
+}
+
+// addTags appends the new tags which are not in tags yet.
+func addTags(tags []string, added []string) []string {
+	for _, tag := range added {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// hasAnyTag checks if any of the tags is one of the wanted ones. Everything matches when no tags are wanted.
+func hasAnyTag(tags []string, wanted []string) bool {
+	return len(wanted) == 0 || slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(wanted, tag) })
+}
+
+// reposWithTags returns the repos which have any of the wanted tags. All repos are returned when no tags are wanted.
+func reposWithTags(repos []*git.Repo, wanted []string) ([]*git.Repo, error) {
+	if len(wanted) == 0 {
+		return repos, nil
+	}
+
+	var res []*git.Repo
+
+	for _, repo := range repos {
+		tags, err := repo.Tags()
+		if err != nil {
+			return nil, err
+		}
+
+		if hasAnyTag(tags, wanted) {
+			res = append(res, repo)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTag(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	path := filepath.Join(root, "repo")
+	require.NoError(t, os.Rename(test.RepoWithCommit(t).Path(), path))
+
+	repo, err := git.Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, Tag(&TagCfg{Path: "repo", Root: root, Tags: []string{"backend", "oncall"}}))
+	require.NoError(t, Tag(&TagCfg{Path: path, Root: root, Tags: []string{"oncall", "infra"}}))
+
+	tags, err := repo.Tags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "oncall", "infra"}, tags)
+
+	require.NoError(t, Tag(&TagCfg{Path: "repo", Root: root, Tags: []string{"oncall"}, Remove: true}))
+
+	tags, err = repo.Tags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "infra"}, tags)
+
+	err = Tag(&TagCfg{Path: "repo", Root: root, Tags: []string{"back,end"}})
+	assert.ErrorIs(t, err, errInvalidTag)
+
+	err = Tag(&TagCfg{Path: "missing", Root: root, Tags: []string{"backend"}})
+	assert.ErrorIs(t, err, errNotARepo)
+}
+
+func TestTagInRoutedRoot(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+	path := filepath.Join(work, "api")
+	require.NoError(t, os.Rename(test.RepoWithCommit(t).Path(), path))
+
+	conf := &TagCfg{Path: "api", Root: root, Tags: []string{"backend"}}
+	require.ErrorIs(t, Tag(conf), errNotARepo)
+
+	// Paths relative to the roots of routes are resolved too.
+	conf.Routes = []cfg.Route{{Pattern: "github.com/mycompany", Root: work}}
+	require.NoError(t, Tag(conf))
+
+	repo, err := git.Open(path)
+	require.NoError(t, err)
+
+	tags, err := repo.Tags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, tags)
+}
+
+func TestSelectStatusesByTag(t *testing.T) {
+	t.Parallel()
+
+	statuses := testStatuses(t, `[
+		{"path": "/root/a", "tags": ["backend", "oncall"]},
+		{"path": "/root/b", "tags": ["infra"]},
+		{"path": "/root/c"}
+	]`)
+
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{tags: nil, want: []string{"/root/a", "/root/b", "/root/c"}},
+		{tags: []string{"infra"}, want: []string{"/root/b"}},
+		{tags: []string{"oncall", "infra"}, want: []string{"/root/a", "/root/b"}},
+		{tags: []string{"frontend"}, want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.tags, ","), func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, status := range selectStatuses(&ListCfg{Sort: cfg.SortPath, Tags: test.tags}, statuses) {
+				got = append(got, status.Path())
+			}
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestGroupByTag(t *testing.T) {
+	t.Parallel()
+
+	statuses := testStatuses(t, `[
+		{"path": "/root/github.com/a", "current": "main", "tags": ["oncall", "backend"]},
+		{"path": "/root/github.com/b", "current": "main", "tags": ["infra"]},
+		{"path": "/root/github.com/c", "current": "main"}
+	]`)
+
+	conf := &ListCfg{Output: cfg.OutTree, GroupBy: cfg.GroupTag, Root: "/root", Sort: cfg.SortPath}
+	require.NoError(t, validate(conf))
+
+	got, err := render(conf, toPrintables(selectStatuses(conf, statuses)))
+	require.NoError(t, err)
+
+	var groups []string
+
+	for _, line := range strings.Split(got, "\n") {
+		if strings.HasPrefix(line, "├── ") || strings.HasPrefix(line, "└── ") {
+			groups = append(groups, line[len("├── "):])
+		}
+	}
+
+	assert.Equal(t, []string{"backend", "infra", "oncall", "(untagged)"}, groups)
+
+	conf.Output = cfg.OutFlat
+	assert.ErrorIs(t, validate(conf), ErrInvalidGroupBy)
+}
+
+func TestGetDumpByTag(t *testing.T) {
+	t.Parallel()
+
+	root := test.TempDir(t, "")
+	backend := test.RepoWithCommit(t)
+	infra := test.RepoWithCommit(t)
+
+	dump := filepath.Join(test.TempDir(t, ""), "repos.txt")
+	writeDumpFile(t, dump, "file://"+backend.Path()+" tags=backend,oncall\nfile://"+infra.Path()+" tags=infra\n")
+
+	require.NoError(t, Get(&GetCfg{Dump: dump, Root: root, Tags: []string{"oncall"}}))
+
+	backendPath := filepath.Join(root, URLToPath(url.URL{Path: backend.Path()}, false))
+	infraPath := filepath.Join(root, URLToPath(url.URL{Path: infra.Path()}, false))
+
+	assert.DirExists(t, filepath.Join(backendPath, ".git"))
+	assert.NoDirExists(t, infraPath)
+
+	require.NoError(t, Get(&GetCfg{Dump: dump, Root: root}))
+	assert.DirExists(t, filepath.Join(infraPath, ".git"))
+}
@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/git"
+	"github.com/grdl/git-get/pkg/out"
+)
+
+// clearScreen moves the cursor to the top-left corner and clears the terminal so the output can be redrawn in place.
+const clearScreen = "\033[H\033[2J"
+
+var errInvalidInterval = errors.New("watch interval must be greater than zero")
+
+// watch periodically reloads the status of all repositories and redraws the output in place until interrupted.
+// Repos are fetched only when FetchInterval has elapsed since the last fetch (or on the first refresh if --fetch is used).
+// Between the periodic reloads, the repos are watched for filesystem changes and only the changed ones are reloaded.
+func watch(conf *ListCfg) error {
+	if conf.Interval <= 0 {
+		return errInvalidInterval
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(conf.Interval)
+	defer ticker.Stop()
+
+	state := newWatchState(conf)
+	defer state.live.stopEvents()
+
+	for {
+		state.reload(ctx)
+		fmt.Print(clearScreen + state.draw())
+
+		if !state.waitForEvents(ctx, ticker.C) {
+			return nil
+		}
+	}
+}
+
+// watchState holds the statuses displayed in watch mode between refreshes.
+type watchState struct {
+	conf      *ListCfg
+	live      *liveStatuses
+	forges    *forgeProviders   // Nil if branches aren't annotated with pull requests and checks.
+	previous  map[string]string // Fingerprints of repos from the previous redraw.
+	lastFetch time.Time
+	refreshed time.Time
+}
+
+// newWatchState returns the state of watch mode for repos under the root and the roots of routes.
+func newWatchState(conf *ListCfg) *watchState {
+	state := &watchState{
+		conf: conf,
+		live: &liveStatuses{roots: allRoots(conf.Root, cfg.Roots(conf.Routes, conf.Root)), opts: loadOpts(conf)},
+	}
+
+	// Forge responses are cached, so redrawing on filesystem events doesn't query the forges each time.
+	if conf.PullRequests && conf.Output != cfg.OutDump {
+		state.forges = newForgeProviders(cfg.WithHosts(&git.Config{}))
+	}
+
+	return state
+}
+
+// reload reloads all repos, fetching them first if it's time to do so.
+func (s *watchState) reload(ctx context.Context) {
+	fetch := s.previous == nil && s.conf.Fetch
+	if s.conf.FetchInterval > 0 && time.Since(s.lastFetch) >= s.conf.FetchInterval {
+		fetch = true
+	}
+
+	if fetch {
+		s.lastFetch = time.Now()
+	}
+
+	s.refreshed = time.Now()
+	s.live.reload(ctx, fetch)
+}
+
+// waitForEvents redraws the output whenever the filesystem watcher reloads some repos.
+// It returns true when it's time for the next periodic reload and false when watch mode should exit.
+func (s *watchState) waitForEvents(ctx context.Context, tick <-chan time.Time) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-tick:
+			return true
+		case updated, ok := <-s.live.updates:
+			s.live.merge(updated, ok)
+
+			if ok {
+				s.refreshed = time.Now()
+				fmt.Print(clearScreen + s.draw())
+			}
+		}
+	}
+}
+
+// draw renders the statuses, highlighting the repos which changed since the previous redraw.
+func (s *watchState) draw() string {
+	header := fmt.Sprintf("Every %s, last refresh: %s\n\n", s.conf.Interval, s.refreshed.Format(time.TimeOnly))
+
+	statuses, err := s.live.get()
+	if err != nil {
+		// Keep watching, the repos might appear (or the roots become accessible) before the next refresh.
+		return header + err.Error() + "\n"
+	}
+
+	printables := toPrintables(selectStatuses(s.conf, statuses))
+	if s.forges != nil {
+		printables = withForgeInfo(printables, s.forges.forHost)
+	}
+
+	printables, current := highlightChanged(printables, s.previous)
+	s.previous = current
+
+	res, err := render(s.conf, printables)
+	if err != nil {
+		return header + err.Error() + "\n"
+	}
+
+	return header + res
+}
+
+// highlightChanged marks printables whose fingerprint differs from the previous refresh.
+// On the first refresh (when previous is nil) nothing is highlighted.
+func highlightChanged(repos []out.Printable, previous map[string]string) ([]out.Printable, map[string]string) {
+	current := make(map[string]string, len(repos))
+	res := make([]out.Printable, len(repos))
+
+	for i, repo := range repos {
+		fp := fingerprint(repo)
+		current[repo.Path()] = fp
+		res[i] = repo
+
+		if previous == nil {
+			continue
+		}
+
+		if prev, ok := previous[repo.Path()]; !ok || prev != fp {
+			res[i] = highlighted{repo}
+		}
+	}
+
+	return res, current
+}
+
+// fingerprint returns a string representing everything that's printed about the repo.
+// Two fingerprints are equal when the repo state hasn't changed.
+func fingerprint(repo out.Printable) string {
+	branches := repo.Branches()
+	sort.Strings(branches)
+
+	parts := []string{
+		repo.Current(), repo.BranchStatus(repo.Current()), repo.WorkTreeStatus(),
+		repo.Operation(), strconv.Itoa(repo.Stashes()),
+		repo.LastCommitDate().String(), repo.LastCommitSubject(),
+	}
+
+	for _, branch := range branches {
+		parts = append(parts, branch, repo.BranchStatus(branch))
+	}
+
+	if annotated, ok := repo.(out.ForgeAnnotated); ok {
+		for _, branch := range append([]string{repo.Current()}, branches...) {
+			number, state := annotated.PullRequest(branch)
+			parts = append(parts, strconv.Itoa(number), state, annotated.Checks(branch))
+		}
+	}
+
+	for _, remote := range repo.Remotes() {
+		parts = append(parts, remote, repo.RemoteBranch(remote), repo.RemoteStatus(remote))
+	}
+
+	parts = append(parts, repo.Errors()...)
+
+	return strings.Join(parts, "\x00")
+}
+
+// highlighted wraps a Printable to mark it as changed since the previous refresh.
+type highlighted struct {
+	out.Printable
+}
+
+// Highlighted implements the out.Highlighter interface.
+func (h highlighted) Highlighted() bool {
+	return true
+}
+
+// PullRequest implements the out.ForgeAnnotated interface by forwarding to the wrapped Printable, if it's annotated.
+func (h highlighted) PullRequest(branch string) (int, string) {
+	if annotated, ok := h.Printable.(out.ForgeAnnotated); ok {
+		return annotated.PullRequest(branch)
+	}
+
+	return 0, ""
+}
+
+// Checks implements the out.ForgeAnnotated interface by forwarding to the wrapped Printable, if it's annotated.
+func (h highlighted) Checks(branch string) string {
+	if annotated, ok := h.Printable.(out.ForgeAnnotated); ok {
+		return annotated.Checks(branch)
+	}
+
+	return ""
+}
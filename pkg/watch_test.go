@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grdl/git-get/pkg/cfg"
+	"github.com/grdl/git-get/pkg/forge"
+	"github.com/grdl/git-get/pkg/git/test"
+	"github.com/grdl/git-get/pkg/out"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepo is a minimal out.Printable used to test the watch mode without creating real repos.
+type fakeRepo struct {
+	path     string
+	current  string
+	branches map[string]string
+	worktree string
+}
+
+func (r *fakeRepo) Path() string                      { return r.path }
+func (r *fakeRepo) Current() string                   { return r.current }
+func (r *fakeRepo) BranchStatus(branch string) string { return r.branches[branch] }
+func (r *fakeRepo) Upstream(string) string            { return "" }
+func (r *fakeRepo) WorkTreeStatus() string            { return r.worktree }
+func (r *fakeRepo) Stashes() int                      { return 0 }
+func (r *fakeRepo) Operation() string                 { return "" }
+func (r *fakeRepo) Remote() string                    { return "" }
+func (r *fakeRepo) Remotes() []string                 { return nil }
+func (r *fakeRepo) RemoteURL(string) string           { return "" }
+func (r *fakeRepo) RemoteBranch(string) string        { return "" }
+func (r *fakeRepo) RemoteStatus(string) string        { return "" }
+func (r *fakeRepo) TrackedRemote() string             { return "" }
+func (r *fakeRepo) Depth() int                        { return 0 }
+func (r *fakeRepo) SparsePaths() []string             { return nil }
+func (r *fakeRepo) Tags() []string                    { return nil }
+func (r *fakeRepo) LastCommitDate() time.Time         { return time.Time{} }
+func (r *fakeRepo) LastCommitAuthor() string          { return "" }
+func (r *fakeRepo) LastCommitSubject() string         { return "" }
+func (r *fakeRepo) LastActivity() time.Time           { return time.Time{} }
+func (r *fakeRepo) WorkTreeSize() int64               { return 0 }
+func (r *fakeRepo) GitSize() int64                    { return 0 }
+func (r *fakeRepo) LFSSize() int64                    { return 0 }
+func (r *fakeRepo) Errors() []string                  { return nil }
+
+func (r *fakeRepo) Branches() []string {
+	var branches []string
+	for b := range r.branches {
+		if b != r.current {
+			branches = append(branches, b)
+		}
+	}
+
+	return branches
+}
+
+func TestHighlightChanged(t *testing.T) {
+	t.Parallel()
+
+	clean := &fakeRepo{path: "/a", current: "main", branches: map[string]string{"main": "", "dev": "1 ahead"}}
+	other := &fakeRepo{path: "/b", current: "main", branches: map[string]string{"main": ""}}
+
+	_, first := highlightChanged([]out.Printable{clean, other}, nil)
+
+	dirty := &fakeRepo{path: "/a", current: "main", branches: map[string]string{"main": "", "dev": "1 ahead"}, worktree: "1 untracked"}
+	added := &fakeRepo{path: "/c", current: "main"}
+
+	got, _ := highlightChanged([]out.Printable{dirty, other, added}, first)
+
+	want := []bool{true, false, true}
+	for i, repo := range got {
+		_, isHighlighted := repo.(out.Highlighter)
+		assert.Equal(t, want[i], isHighlighted, "repo %s", repo.Path())
+	}
+}
+
+func TestHighlightChangedFirstRefresh(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepo{path: "/a", current: "main", worktree: "1 untracked"}
+
+	got, _ := highlightChanged([]out.Printable{repo}, nil)
+
+	_, isHighlighted := got[0].(out.Highlighter)
+	assert.False(t, isHighlighted)
+}
+
+func TestFingerprintIgnoresBranchOrder(t *testing.T) {
+	t.Parallel()
+
+	branches := map[string]string{"main": "", "a": "1 ahead", "b": "2 behind", "c": "no upstream"}
+	repo := &fakeRepo{path: "/a", current: "main", branches: branches}
+
+	want := fingerprint(repo)
+	for range 10 {
+		assert.Equal(t, want, fingerprint(repo))
+	}
+}
+
+func TestWatchDrawShowsForgeInfo(t *testing.T) {
+	t.Parallel()
+
+	statuses := testStatuses(t, `[
+		{"path": "/repos/github.com/grdl/git-get", "current": "main", "branches": {"main": ""}, "upstreams": {"main": "origin/main"},
+		 "remote": "git@github.com:grdl/git-get.git"}
+	]`)
+
+	fake := &fakeForge{infos: map[string]forge.BranchInfo{
+		"grdl/git-get:main": {PullRequest: &forge.PullRequest{Number: 12, State: forge.StateOpen}},
+	}}
+
+	state := &watchState{
+		conf:   &ListCfg{Output: cfg.OutFlat, Interval: time.Second},
+		live:   &liveStatuses{statuses: statuses},
+		forges: &forgeProviders{providers: map[string]forge.Provider{"github.com": fake}},
+	}
+
+	assert.Contains(t, state.draw(), "PR #12 open")
+
+	// Changed repos are highlighted and still show their pull requests.
+	state.previous = map[string]string{}
+	assert.Contains(t, state.draw(), "PR #12 open")
+}
+
+func TestWatchTwoRoots(t *testing.T) {
+	t.Parallel()
+
+	personal := test.TempDir(t, "")
+	work := test.TempDir(t, "")
+
+	dotfiles := repoWithRemote(t, personal, "dotfiles", "git@github.com:grdl/dotfiles.git")
+	api := repoWithRemote(t, work, "api", "git@github.com:mycompany/api.git")
+
+	state := newWatchState(&ListCfg{
+		Output:   cfg.OutFlat,
+		Interval: time.Second,
+		Root:     personal,
+		Routes:   []cfg.Route{{Pattern: "github.com/mycompany", Root: work}},
+	})
+	defer state.live.stopEvents()
+
+	state.reload(context.Background())
+
+	res := state.draw()
+	assert.Contains(t, res, dotfiles)
+	assert.Contains(t, res, api)
+	assert.ElementsMatch(t, []string{dotfiles, api}, state.live.repoPaths, "repos under both roots are watched for changes")
+}